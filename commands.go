@@ -0,0 +1,122 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"gin-swagger/dao"
+	"gin-swagger/i18n"
+	"gin-swagger/model"
+	"gin-swagger/search"
+	"gin-swagger/transfer"
+	"io/ioutil"
+	"os"
+)
+
+// command 命令行子命令
+type command struct {
+	usage string
+	run   func(args []string) error
+}
+
+var commands = map[string]command{
+	"reindex": {usage: "reindex  从数据库重建文章检索索引", run: reindex},
+	"import":  {usage: "import -file <path> -user <id> [-format ndjson|csv|markdown] [-dry-run]  导入文章", run: importPosts},
+}
+
+// RunCommand 执行命令行子命令，执行失败时以非零状态退出
+func RunCommand(args []string) {
+	cmd, ok := commands[args[0]]
+	if !ok {
+		fmt.Fprintf(os.Stderr, "unknown command %q, available commands:\n", args[0])
+		for _, c := range commands {
+			fmt.Fprintln(os.Stderr, "  "+c.usage)
+		}
+		os.Exit(2)
+	}
+
+	if err := cmd.run(args[1:]); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+}
+
+// reindex 重建检索索引，需在服务停止时执行以免索引文件被占用
+func reindex(args []string) error {
+	db := dao.GetDB()
+	if err := db.AutoMigrate(model.Post{}); err != nil {
+		return err
+	}
+
+	total, err := search.Rebuild(search.GetSearcher(), db)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("reindexed %d posts\n", total)
+	return search.GetSearcher().Close()
+}
+
+// importPosts 从文件导入文章，任一条失败时以非零状态退出
+func importPosts(args []string) error {
+	fs := flag.NewFlagSet("import", flag.ContinueOnError)
+	file := fs.String("file", "", "导入文件路径")
+	format := fs.String("format", "", "导入格式，默认按文件扩展名判断")
+	userID := fs.Uint("user", 0, "文章作者的用户ID")
+	dryRun := fs.Bool("dry-run", false, "只校验不写入")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if *file == "" || *userID == 0 {
+		fs.Usage()
+		return errors.New("-file and -user are required")
+	}
+	if *format == "" {
+		*format = transfer.FormatOf(*file)
+	}
+
+	db := dao.GetDB()
+	if err := db.AutoMigrate(model.Tag{}, model.Post{}); err != nil {
+		return err
+	}
+	var user model.User
+	if err := db.First(&user, *userID).Error; err != nil {
+		return fmt.Errorf("user %d not found", *userID)
+	}
+
+	data, err := ioutil.ReadFile(*file)
+	if err != nil {
+		return err
+	}
+	items, err := transfer.Parse(*format, data)
+	if err != nil {
+		return err
+	}
+
+	importer := transfer.Importer{DB: db, UserID: user.ID, DryRun: *dryRun, Lang: i18n.DefaultLanguage()}
+	results, created := importer.Import(items)
+	failed := 0
+	for _, result := range results {
+		if !result.Success {
+			failed++
+		}
+		fmt.Printf("%s\t%s\t%s\n", result.Source, result.Title, result.Msg)
+	}
+	if len(created) > 0 {
+		if err := search.GetSearcher().Index(created...); err != nil {
+			return err
+		}
+	}
+	if *dryRun {
+		fmt.Printf("validated %d of %d records\n", len(results)-failed, len(results))
+	} else {
+		fmt.Printf("imported %d of %d records\n", len(created), len(results))
+	}
+
+	if err := search.GetSearcher().Close(); err != nil {
+		return err
+	}
+	if failed > 0 {
+		return fmt.Errorf("%d records failed", failed)
+	}
+	return nil
+}
@@ -0,0 +1,219 @@
+package controller
+
+import (
+	"errors"
+	"gin-swagger/dao"
+	"gin-swagger/errcode"
+	"gin-swagger/dto"
+	"gin-swagger/i18n"
+	"gin-swagger/logger"
+	"gin-swagger/model"
+	"gin-swagger/response"
+	"gin-swagger/search"
+	"gin-swagger/sitemap"
+	"github.com/gin-gonic/gin"
+	"github.com/spf13/viper"
+	"gorm.io/gorm"
+)
+
+const defaultBulkMaxItems = 100
+
+type IBulkController interface {
+	Posts(ctx *gin.Context)
+}
+
+type BulkController struct {
+	DB       *gorm.DB
+	Searcher search.Searcher
+	Sitemap  *sitemap.Generator
+}
+
+func NewBulkController() IBulkController {
+	return BulkController{DB: dao.GetDB(), Searcher: search.GetSearcher(), Sitemap: sitemap.GetGenerator()}
+}
+
+// Posts 批量操作文章模块
+// @Summary 批量操作文章接口
+// @Schemes
+// @Description 批量删除、移动分类、修改状态、添加或移除标签，逐条校验作者权限并返回每条结果；atomic 为 true 时任一条失败则全部回滚
+// @Tags 批量操作文章
+// @Accept application/json
+// @Produce application/json
+// @Param Authorization header string false "Bearer 用户令牌"
+// @Param object body dto.BulkPostRequest true "批量操作参数"
+// @Success 200 {string} string "批量操作完成"
+// @Failure 400 {string} string "数据验证错误"
+// @Router /posts/bulk [post]
+func (b BulkController) Posts(ctx *gin.Context) {
+	db := b.DB.WithContext(ctx.Request.Context())
+	var requestBulk dto.BulkPostRequest
+	if err := ctx.ShouldBind(&requestBulk); err != nil {
+		ctx.Error(errcode.Invalid(err))
+		return
+	}
+
+	maxItems := viper.GetInt("post.bulk_max_items")
+	if maxItems <= 0 {
+		maxItems = defaultBulkMaxItems
+	}
+	if len(requestBulk.IDs) > maxItems {
+		ctx.Error(errcode.New(errcode.BulkTooManyItems, maxItems))
+		return
+	}
+
+	// 校验操作参数
+	switch requestBulk.Operation {
+	case "move_category":
+		var category model.Category
+		if err := db.First(&category, requestBulk.CategoryID).Error; err != nil {
+			ctx.Error(errcode.New(errcode.CategoryNotFound))
+			return
+		}
+	case "change_status":
+		if requestBulk.Status == "" {
+			ctx.Error(errcode.New(errcode.BulkStatusRequired))
+			return
+		}
+	case "add_tags", "remove_tags":
+		if len(requestBulk.Tags) == 0 {
+			ctx.Error(errcode.New(errcode.BulkTagsRequired))
+			return
+		}
+	}
+
+	user, _ := ctx.Get("user")
+	userID := user.(model.User).ID
+	lang := i18n.FromContext(ctx)
+	results := make([]dto.BulkItemResult, len(requestBulk.IDs))
+	var changed []model.Post
+
+	// 逐条处理，返回 *errcode.Error 表示该条因业务原因失败
+	process := func(tx *gorm.DB, i int) error {
+		id := requestBulk.IDs[i]
+		results[i].ID = id
+
+		var post model.Post
+		if err := tx.Where("id = ?", id).First(&post).Error; err != nil {
+			return errcode.New(errcode.PostNotFound)
+		}
+		if post.UserID != userID {
+			return errcode.New(errcode.NotPostAuthor)
+		}
+		if err := b.apply(tx, &post, requestBulk); err != nil {
+			return err
+		}
+		changed = append(changed, post)
+		return nil
+	}
+	fail := func(i int, err error) {
+		var itemErr *errcode.Error
+		if !errors.As(err, &itemErr) {
+			logger.FromContext(ctx).Error("bulk operation failed", "operation", requestBulk.Operation, "post_id", requestBulk.IDs[i], "error", err)
+			itemErr = errcode.Wrap(err, errcode.OperationFailed)
+		}
+		results[i].Error = itemErr.Code
+		results[i].Msg = itemErr.Message(lang)
+	}
+
+	if requestBulk.Atomic {
+		err := db.Transaction(func(tx *gorm.DB) error {
+			for i := range requestBulk.IDs {
+				if err := process(tx, i); err != nil {
+					fail(i, err)
+					return err
+				}
+				results[i].Success = true
+				results[i].Msg = i18n.T(lang, "bulk_item_succeeded")
+			}
+			return nil
+		})
+		if err != nil {
+			for i := range results {
+				results[i].ID = requestBulk.IDs[i]
+				if results[i].Success || results[i].Msg == "" {
+					results[i].Success = false
+					results[i].Msg = i18n.T(lang, "bulk_item_rolled_back")
+				}
+			}
+			ctx.Error(errcode.New(errcode.BulkRolledBack).WithData(gin.H{"results": results}))
+			return
+		}
+	} else {
+		for i := range requestBulk.IDs {
+			err := db.Transaction(func(tx *gorm.DB) error {
+				return process(tx, i)
+			})
+			if err != nil {
+				fail(i, err)
+				continue
+			}
+			results[i].Success = true
+			results[i].Msg = i18n.T(lang, "bulk_item_succeeded")
+		}
+	}
+
+	b.sync(ctx, requestBulk.Operation, changed)
+
+	succeeded := len(changed)
+	response.Success(ctx, gin.H{
+		"results":   results,
+		"succeeded": succeeded,
+		"failed":    len(results) - succeeded,
+	}, "bulk_completed")
+}
+
+// apply 对单篇文章执行批量操作
+func (b BulkController) apply(tx *gorm.DB, post *model.Post, requestBulk dto.BulkPostRequest) error {
+	switch requestBulk.Operation {
+	case "delete":
+		return tx.Delete(post).Error
+	case "move_category":
+		return tx.Model(post).Update("category_id", requestBulk.CategoryID).Error
+	case "change_status":
+		return tx.Model(post).Update("status", requestBulk.Status).Error
+	case "add_tags":
+		tags := make([]model.Tag, 0, len(requestBulk.Tags))
+		for _, name := range requestBulk.Tags {
+			tag := model.Tag{Name: name}
+			if err := tx.Where(model.Tag{Name: name}).FirstOrCreate(&tag).Error; err != nil {
+				return err
+			}
+			tags = append(tags, tag)
+		}
+		return tx.Model(post).Association("Tags").Append(tags)
+	case "remove_tags":
+		var tags []model.Tag
+		if err := tx.Where("name IN ?", requestBulk.Tags).Find(&tags).Error; err != nil {
+			return err
+		}
+		if len(tags) == 0 {
+			return nil
+		}
+		return tx.Model(post).Association("Tags").Delete(tags)
+	}
+	return errcode.New(errcode.BulkUnsupportedOperation)
+}
+
+// sync 操作成功后同步检索索引和站点地图
+func (b BulkController) sync(ctx *gin.Context, operation string, posts []model.Post) {
+	if len(posts) == 0 {
+		return
+	}
+
+	switch operation {
+	case "delete":
+		ids := make([]string, 0, len(posts))
+		for _, post := range posts {
+			ids = append(ids, post.ID.String())
+		}
+		if err := b.Searcher.Delete(ids...); err != nil {
+			logger.FromContext(ctx).Error("delete posts from index failed", "error", err)
+		}
+		b.Sitemap.Remove(ids...)
+	case "move_category", "change_status":
+		// 索引中的状态用于检索时过滤草稿
+		if err := b.Searcher.Index(posts...); err != nil {
+			logger.FromContext(ctx).Error("index posts failed", "error", err)
+		}
+	}
+}
@@ -3,11 +3,12 @@ package controller
 import (
 	"gin-swagger/dao"
 	"gin-swagger/dto"
+	"gin-swagger/errcode"
 	"gin-swagger/model"
 	"gin-swagger/response"
+	"gin-swagger/sitemap"
 	"github.com/gin-gonic/gin"
 	"gorm.io/gorm"
-	"log"
 	"strconv"
 )
 
@@ -17,13 +18,14 @@ type ICategoryController interface {
 
 type CategoryController struct {
 	DB *gorm.DB
+	Sitemap *sitemap.Generator
 }
 
 func NewCategoryController() ICategoryController {
 	db := dao.GetDB()
 	db.AutoMigrate(model.Category{})
 
-	return CategoryController{DB:db}
+	return CategoryController{DB:db, Sitemap: sitemap.GetGenerator()}
 }
 
 // Create 创建类别模块
@@ -34,20 +36,24 @@ func NewCategoryController() ICategoryController {
 // @Accept application/json
 // @Produce application/json
 // @Param object query dto.CreateCategoryRequest false "创建参数"
+// @Param Idempotency-Key header string false "幂等键，重试时使用相同的值"
 // @Success 200 {string} string "分类创建成功"
-// @Failure 400 {string} string "数据验证错误，分类名称必填"
+// @Failure 400 {string} string "数据验证错误"
 // @Router /categories [post]
 func (c CategoryController) Create(ctx *gin.Context) {
+	db := c.DB.WithContext(ctx.Request.Context())
 	var requestCategory dto.CreateCategoryRequest
 	if err := ctx.ShouldBind(&requestCategory); err != nil {
-		response.Fail(ctx, nil,"数据验证错误，分类名称必填")
+		ctx.Error(errcode.Invalid(err))
 		return
 	}
 
 	category := model.Category{Name: requestCategory.Name}
-	log.Println(category)
-	c.DB.Create(&category)
-	response.Success(ctx, gin.H{"category": requestCategory}, "分类创建成功")
+	if err := db.Create(&category).Error; err != nil {
+		ctx.Error(errcode.Wrap(err, errcode.Internal))
+		return
+	}
+	response.Success(ctx, gin.H{"category": requestCategory}, "category_created")
 }
 
 // Update 更新类别模块
@@ -60,13 +66,14 @@ func (c CategoryController) Create(ctx *gin.Context) {
 // @Param id path integer true "类别ID"
 // @Param object query dto.CreateCategoryRequest false "查询参数"
 // @Success 200 {string} string "修改分类成功"
-// @Failure 400 {string} string "分类不存在"
+// @Failure 404 {string} string "分类不存在"
 // @Router /categories/{id} [put]
 func (c CategoryController) Update(ctx *gin.Context) {
+	db := c.DB.WithContext(ctx.Request.Context())
 	// 绑定body中的参数
 	var requestCategory dto.CreateCategoryRequest
 	if err := ctx.ShouldBind(&requestCategory); err != nil {
-		response.Fail(ctx, nil,"数据验证错误，分类名称必填")
+		ctx.Error(errcode.Invalid(err))
 		return
 	}
 
@@ -74,17 +81,20 @@ func (c CategoryController) Update(ctx *gin.Context) {
 	categoryID, _ := strconv.Atoi(ctx.Params.ByName("id"))
 
 	var updateCategory model.Category
-	err := c.DB.First(&updateCategory, categoryID).Error
+	err := db.First(&updateCategory, categoryID).Error
 	if err != nil {
-		response.Fail(ctx, nil, "分类不存在")
+		ctx.Error(errcode.New(errcode.CategoryNotFound))
 		return
 	}
 
 	// 更新分类
 	// map, struct, name value
-	c.DB.Model(&updateCategory).Update("name", requestCategory.Name)
+	if err := db.Model(&updateCategory).Update("name", requestCategory.Name).Error; err != nil {
+		ctx.Error(errcode.Wrap(err, errcode.OperationFailed))
+		return
+	}
 
-	response.Success(ctx, gin.H{"category": updateCategory}, "修改分类成功")
+	response.Success(ctx, gin.H{"category": updateCategory}, "category_updated")
 }
 
 // Show 查看类别模块
@@ -96,20 +106,21 @@ func (c CategoryController) Update(ctx *gin.Context) {
 // @Produce application/json
 // @Param id path integer true "类别ID"
 // @Success 200 {string} string "分类查看成功"
-// @Failure 400 {string} string "分类不存在"
+// @Failure 404 {string} string "分类不存在"
 // @Router /categories/{id} [get]
 func (c CategoryController) Show(ctx *gin.Context) {
+	db := c.DB.WithContext(ctx.Request.Context())
 	// 获取path中的参数
 	categoryID, _ := strconv.Atoi(ctx.Params.ByName("id"))
 
 	var category model.Category
-	err := c.DB.First(&category, categoryID).Error
+	err := db.First(&category, categoryID).Error
 	if err != nil {
-		response.Fail(ctx, nil, "分类不存在")
+		ctx.Error(errcode.New(errcode.CategoryNotFound))
 		return
 	}
 
-	response.Success(ctx, gin.H{"category": category}, "查询成功")
+	response.Success(ctx, gin.H{"category": category}, "category_shown")
 }
 
 // Delete 删除类别模块
@@ -121,17 +132,19 @@ func (c CategoryController) Show(ctx *gin.Context) {
 // @Produce application/json
 // @Param id path integer true "类别ID"
 // @Success 200 {string} string "分类删除成功"
-// @Failure 400 {string} string "删除失败，请重试"
+// @Failure 500 {string} string "删除失败，请重试"
 // @Router /categories/{id} [delete]
 func (c CategoryController) Delete(ctx *gin.Context) {
+	db := c.DB.WithContext(ctx.Request.Context())
 	// 获取path中的参数
 	categoryID, _ := strconv.Atoi(ctx.Params.ByName("id"))
 
-	err := c.DB.Delete(model.Category{}, categoryID).Error
+	err := db.Delete(model.Category{}, categoryID).Error
 	if err != nil {
-		response.Fail(ctx, nil, "删除失败，请重试")
+		ctx.Error(errcode.Wrap(err, errcode.CategoryDeleteFailed))
 		return
 	}
-	response.Success(ctx, nil, "删除成功")
+	c.Sitemap.RemoveCategory(uint(categoryID))
+	response.Success(ctx, nil, "category_deleted")
 }
 
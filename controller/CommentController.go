@@ -0,0 +1,404 @@
+package controller
+
+import (
+	"gin-swagger/dao"
+	"gin-swagger/errcode"
+	"gin-swagger/dto"
+	"gin-swagger/model"
+	"gin-swagger/response"
+	"github.com/gin-gonic/gin"
+	"github.com/spf13/viper"
+	"gorm.io/gorm"
+	"strconv"
+)
+
+type ICommentController interface {
+	RestController
+	PageList(ctx *gin.Context)
+	ModerationList(ctx *gin.Context)
+	Moderate(ctx *gin.Context)
+}
+
+type CommentController struct {
+	DB *gorm.DB
+}
+
+func NewCommentController() ICommentController {
+	db := dao.GetDB()
+	db.AutoMigrate(model.Comment{})
+	return CommentController{DB: db}
+}
+
+// initialStatus 新评论及编辑后评论的审核状态
+func initialStatus() string {
+	if viper.GetBool("comment.auto_approve") {
+		return model.CommentStatusApproved
+	}
+	return model.CommentStatusPending
+}
+
+// Create 发表评论模块
+// @Summary 发表评论接口
+// @Schemes
+// @Description 发表评论或回复评论，parent_id 为空时为顶层评论
+// @Tags 发表评论
+// @Accept application/json
+// @Produce application/json
+// @Param Authorization header string false "Bearer 用户令牌"
+// @Param id path string true "文章ID"
+// @Param object query dto.CreateCommentRequest false "评论参数"
+// @Success 200 {string} string "评论成功"
+// @Failure 400 {string} string "数据验证错误"
+// @Router /posts/{id}/comments [post]
+func (c CommentController) Create(ctx *gin.Context) {
+	db := c.DB.WithContext(ctx.Request.Context())
+	var requestComment dto.CreateCommentRequest
+	if err := ctx.ShouldBind(&requestComment); err != nil {
+		ctx.Error(errcode.Invalid(err))
+		return
+	}
+
+	// 获取path 中的文章id
+	postID := ctx.Params.ByName("id")
+	var post model.Post
+	if err := db.Scopes(visiblePostScope(ctx)).Where("id = ?", postID).First(&post).Error; err != nil {
+		ctx.Error(errcode.New(errcode.PostNotFound))
+		return
+	}
+	if post.CommentsDisabled {
+		ctx.Error(errcode.New(errcode.CommentsDisabled))
+		return
+	}
+
+	user, _ := ctx.Get("user")
+	comment := model.Comment{
+		PostID:  post.ID,
+		UserID:  user.(model.User).ID,
+		Content: requestComment.Content,
+		Status:  initialStatus(),
+	}
+
+	// 回复评论时，父评论需属于同一文章且已通过审核
+	if requestComment.ParentID != nil {
+		var parent model.Comment
+		err := db.Where("id = ? AND post_id = ? AND status = ?", *requestComment.ParentID, post.ID, model.CommentStatusApproved).
+			First(&parent).Error
+		if err != nil {
+			ctx.Error(errcode.New(errcode.ParentCommentNotFound))
+			return
+		}
+		comment.ParentID = &parent.ID
+		comment.RootID = parent.RootID
+		if comment.RootID == nil {
+			comment.RootID = &parent.ID
+		}
+	}
+
+	if err := db.Create(&comment).Error; err != nil {
+		ctx.Error(errcode.Wrap(err, errcode.CommentCreateFailed))
+		return
+	}
+
+	response.Success(ctx, gin.H{"comment": comment}, "comment_created")
+}
+
+// Update 编辑评论模块
+// @Summary 编辑评论接口
+// @Schemes
+// @Description 评论作者编辑评论，未开启自动审核时需重新审核
+// @Tags 编辑评论
+// @Accept application/json
+// @Produce application/json
+// @Param Authorization header string false "Bearer 用户令牌"
+// @Param id path integer true "评论ID"
+// @Param object query dto.UpdateCommentRequest false "评论参数"
+// @Success 200 {string} string "修改成功"
+// @Failure 404 {string} string "评论不存在"
+// @Router /comments/{id} [put]
+func (c CommentController) Update(ctx *gin.Context) {
+	db := c.DB.WithContext(ctx.Request.Context())
+	var requestComment dto.UpdateCommentRequest
+	if err := ctx.ShouldBind(&requestComment); err != nil {
+		ctx.Error(errcode.Invalid(err))
+		return
+	}
+
+	commentID, _ := strconv.Atoi(ctx.Params.ByName("id"))
+	var comment model.Comment
+	if err := db.First(&comment, commentID).Error; err != nil {
+		ctx.Error(errcode.New(errcode.CommentNotFound))
+		return
+	}
+	if !c.postVisible(ctx, db, comment) {
+		ctx.Error(errcode.New(errcode.PostNotFound))
+		return
+	}
+
+	// 判断当前用户是否为评论作者
+	user, _ := ctx.Get("user")
+	if user.(model.User).ID != comment.UserID {
+		ctx.Error(errcode.New(errcode.NotCommentAuthor))
+		return
+	}
+
+	err := db.Model(&comment).Updates(model.Comment{
+		Content: requestComment.Content,
+		Status:  initialStatus(),
+	}).Error
+	if err != nil {
+		ctx.Error(errcode.Wrap(err, errcode.CommentUpdateFailed))
+		return
+	}
+
+	response.Success(ctx, gin.H{"comment": comment}, "comment_updated")
+}
+
+// Show 查看评论模块
+// @Summary 查看评论接口
+// @Schemes
+// @Description 查看单条评论，未通过审核的评论仅作者可见
+// @Tags 查看评论
+// @Accept application/json
+// @Produce application/json
+// @Param Authorization header string false "Bearer 用户令牌"
+// @Param id path integer true "评论ID"
+// @Success 200 {string} string "查看成功"
+// @Failure 404 {string} string "评论不存在"
+// @Router /comments/{id} [get]
+func (c CommentController) Show(ctx *gin.Context) {
+	db := c.DB.WithContext(ctx.Request.Context())
+	commentID, _ := strconv.Atoi(ctx.Params.ByName("id"))
+	var comment model.Comment
+	if err := db.First(&comment, commentID).Error; err != nil {
+		ctx.Error(errcode.New(errcode.CommentNotFound))
+		return
+	}
+	if !c.postVisible(ctx, db, comment) {
+		ctx.Error(errcode.New(errcode.PostNotFound))
+		return
+	}
+
+	user, _ := ctx.Get("user")
+	if comment.Status != model.CommentStatusApproved && user.(model.User).ID != comment.UserID {
+		ctx.Error(errcode.New(errcode.CommentNotFound))
+		return
+	}
+
+	response.Success(ctx, gin.H{"comment": comment}, "comment_shown")
+}
+
+// Delete 删除评论模块
+// @Summary 删除评论接口
+// @Schemes
+// @Description 评论作者或管理员删除评论，评论下的回复一并删除
+// @Tags 删除评论
+// @Accept application/json
+// @Produce application/json
+// @Param Authorization header string false "Bearer 用户令牌"
+// @Param id path integer true "评论ID"
+// @Success 200 {string} string "删除成功"
+// @Failure 400 {string} string "删除失败"
+// @Router /comments/{id} [delete]
+func (c CommentController) Delete(ctx *gin.Context) {
+	db := c.DB.WithContext(ctx.Request.Context())
+	commentID, _ := strconv.Atoi(ctx.Params.ByName("id"))
+	var comment model.Comment
+	if err := db.First(&comment, commentID).Error; err != nil {
+		ctx.Error(errcode.New(errcode.CommentNotFound))
+		return
+	}
+	if !c.postVisible(ctx, db, comment) {
+		ctx.Error(errcode.New(errcode.PostNotFound))
+		return
+	}
+
+	user, _ := ctx.Get("user")
+	if user.(model.User).ID != comment.UserID && !user.(model.User).IsAdmin {
+		ctx.Error(errcode.New(errcode.NotCommentAuthor))
+		return
+	}
+
+	// 收集该评论及其全部后代回复
+	rootID := comment.ID
+	if comment.RootID != nil {
+		rootID = *comment.RootID
+	}
+	var thread []model.Comment
+	db.Where("root_id = ?", rootID).Find(&thread)
+	ids := descendantIDs(thread, comment.ID)
+
+	if err := db.Delete(&model.Comment{}, ids).Error; err != nil {
+		ctx.Error(errcode.Wrap(err, errcode.CommentDeleteFailed))
+		return
+	}
+	response.Success(ctx, nil, "comment_deleted")
+}
+
+// PageList 列出文章评论模块
+// @Summary 列出文章评论接口
+// @Schemes
+// @Description 分页列出文章的顶层评论，每条附带已审核的回复树
+// @Tags 列出评论
+// @Accept application/json
+// @Produce application/json
+// @Param Authorization header string false "Bearer 用户令牌"
+// @Param id path string true "文章ID"
+// @Param pageNum query integer false "页码"
+// @Param pageSize query integer false "每页条数"
+// @Success 200 {string} string "成功"
+// @Failure 404 {string} string "文章不存在"
+// @Router /posts/{id}/comments [get]
+func (c CommentController) PageList(ctx *gin.Context) {
+	db := c.DB.WithContext(ctx.Request.Context())
+	postID := ctx.Params.ByName("id")
+	var post model.Post
+	if err := db.Scopes(visiblePostScope(ctx)).Where("id = ?", postID).First(&post).Error; err != nil {
+		ctx.Error(errcode.New(errcode.PostNotFound))
+		return
+	}
+
+	// 获取分页参数
+	pageNum, _ := strconv.Atoi(ctx.DefaultQuery("pageNum", "1"))
+	pageSize, _ := strconv.Atoi(ctx.DefaultQuery("pageSize", "20"))
+
+	// 分页查询顶层评论
+	query := db.Model(model.Comment{}).
+		Where("post_id = ? AND parent_id IS NULL AND status = ?", post.ID, model.CommentStatusApproved)
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		ctx.Error(errcode.Wrap(err, errcode.Internal))
+		return
+	}
+	var roots []*model.Comment
+	if err := query.Order("created_at desc").Offset((pageNum - 1) * pageSize).Limit(pageSize).Find(&roots).Error; err != nil {
+		ctx.Error(errcode.Wrap(err, errcode.Internal))
+		return
+	}
+
+	// 加载整楼回复并组装成树
+	rootIDs := make([]uint, 0, len(roots))
+	for _, root := range roots {
+		rootIDs = append(rootIDs, root.ID)
+	}
+	var replies []*model.Comment
+	if len(rootIDs) > 0 {
+		err := db.Where("root_id IN ? AND status = ?", rootIDs, model.CommentStatusApproved).
+			Order("created_at asc").Find(&replies).Error
+		if err != nil {
+			ctx.Error(errcode.Wrap(err, errcode.Internal))
+			return
+		}
+	}
+	buildThreads(roots, replies)
+
+	response.Success(ctx, gin.H{"data": roots, "total": total}, "success")
+}
+
+// ModerationList 评论审核列表模块
+// @Summary 评论审核列表接口
+// @Schemes
+// @Description 管理员按审核状态分页列出评论
+// @Tags 评论审核
+// @Accept application/json
+// @Produce application/json
+// @Param Authorization header string false "Bearer 用户令牌"
+// @Param status query string false "审核状态 pending/approved/spam"
+// @Param pageNum query integer false "页码"
+// @Param pageSize query integer false "每页条数"
+// @Success 200 {string} string "成功"
+// @Failure 403 {string} string "需要管理员权限"
+// @Router /admin/comments [get]
+func (c CommentController) ModerationList(ctx *gin.Context) {
+	db := c.DB.WithContext(ctx.Request.Context())
+	status := ctx.DefaultQuery("status", model.CommentStatusPending)
+	pageNum, _ := strconv.Atoi(ctx.DefaultQuery("pageNum", "1"))
+	pageSize, _ := strconv.Atoi(ctx.DefaultQuery("pageSize", "20"))
+
+	query := db.Model(model.Comment{}).Where("status = ?", status)
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		ctx.Error(errcode.Wrap(err, errcode.Internal))
+		return
+	}
+	var comments []model.Comment
+	if err := query.Order("created_at asc").Offset((pageNum - 1) * pageSize).Limit(pageSize).Find(&comments).Error; err != nil {
+		ctx.Error(errcode.Wrap(err, errcode.Internal))
+		return
+	}
+
+	response.Success(ctx, gin.H{"data": comments, "total": total}, "success")
+}
+
+// Moderate 审核评论模块
+// @Summary 审核评论接口
+// @Schemes
+// @Description 管理员修改评论审核状态
+// @Tags 评论审核
+// @Accept application/json
+// @Produce application/json
+// @Param Authorization header string false "Bearer 用户令牌"
+// @Param id path integer true "评论ID"
+// @Param object query dto.ModerateCommentRequest false "审核参数"
+// @Success 200 {string} string "审核成功"
+// @Failure 404 {string} string "评论不存在"
+// @Router /admin/comments/{id}/status [put]
+func (c CommentController) Moderate(ctx *gin.Context) {
+	db := c.DB.WithContext(ctx.Request.Context())
+	var requestModerate dto.ModerateCommentRequest
+	if err := ctx.ShouldBind(&requestModerate); err != nil {
+		ctx.Error(errcode.Invalid(err))
+		return
+	}
+
+	commentID, _ := strconv.Atoi(ctx.Params.ByName("id"))
+	var comment model.Comment
+	if err := db.First(&comment, commentID).Error; err != nil {
+		ctx.Error(errcode.New(errcode.CommentNotFound))
+		return
+	}
+
+	if err := db.Model(&comment).Update("status", requestModerate.Status).Error; err != nil {
+		ctx.Error(errcode.Wrap(err, errcode.CommentModerateFailed))
+		return
+	}
+
+	response.Success(ctx, gin.H{"comment": comment}, "comment_moderated")
+}
+
+// postVisible 评论所属文章对当前用户可见，他人草稿和回收站中文章的评论按文章不存在处理
+func (c CommentController) postVisible(ctx *gin.Context, db *gorm.DB, comment model.Comment) bool {
+	var post model.Post
+	return db.Scopes(visiblePostScope(ctx)).Where("id = ?", comment.PostID).First(&post).Error == nil
+}
+
+// buildThreads 将回复挂到各自的父评论下
+func buildThreads(roots []*model.Comment, replies []*model.Comment) {
+	nodes := make(map[uint]*model.Comment, len(roots)+len(replies))
+	for _, root := range roots {
+		nodes[root.ID] = root
+	}
+	for _, reply := range replies {
+		nodes[reply.ID] = reply
+	}
+	for _, reply := range replies {
+		if parent, ok := nodes[*reply.ParentID]; ok {
+			parent.Replies = append(parent.Replies, reply)
+		}
+	}
+}
+
+// descendantIDs 返回 id 本身及其在楼层中的全部后代评论ID
+func descendantIDs(thread []model.Comment, id uint) []uint {
+	children := make(map[uint][]uint)
+	for _, comment := range thread {
+		if comment.ParentID != nil {
+			children[*comment.ParentID] = append(children[*comment.ParentID], comment.ID)
+		}
+	}
+
+	ids := []uint{id}
+	for i := 0; i < len(ids); i++ {
+		ids = append(ids, children[ids[i]]...)
+	}
+	return ids
+}
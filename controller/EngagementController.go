@@ -0,0 +1,182 @@
+package controller
+
+import (
+	"gin-swagger/dao"
+	"gin-swagger/errcode"
+	"gin-swagger/model"
+	"gin-swagger/response"
+	"github.com/gin-gonic/gin"
+	uuid "github.com/satori/go.uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"strconv"
+)
+
+type IEngagementController interface {
+	Like(ctx *gin.Context)
+	Unlike(ctx *gin.Context)
+	Bookmark(ctx *gin.Context)
+	Unbookmark(ctx *gin.Context)
+	Bookmarks(ctx *gin.Context)
+}
+
+type EngagementController struct {
+	DB *gorm.DB
+}
+
+func NewEngagementController() IEngagementController {
+	db := dao.GetDB()
+	db.AutoMigrate(model.PostLike{}, model.PostBookmark{})
+	return EngagementController{DB: db}
+}
+
+// Like 点赞文章模块
+// @Summary 点赞文章接口
+// @Schemes
+// @Description 点赞文章，重复点赞不会重复计数
+// @Tags 文章点赞
+// @Accept application/json
+// @Produce application/json
+// @Param Authorization header string false "Bearer 用户令牌"
+// @Param id path string true "文章ID"
+// @Success 200 {string} string "点赞成功"
+// @Failure 404 {string} string "文章不存在"
+// @Router /posts/{id}/like [put]
+func (e EngagementController) Like(ctx *gin.Context) {
+	e.toggle(ctx, newPostLike, "like_count", true, "like_added")
+}
+
+// Unlike 取消点赞模块
+// @Summary 取消点赞接口
+// @Schemes
+// @Description 取消点赞文章，未点赞时直接返回成功
+// @Tags 文章点赞
+// @Accept application/json
+// @Produce application/json
+// @Param Authorization header string false "Bearer 用户令牌"
+// @Param id path string true "文章ID"
+// @Success 200 {string} string "取消点赞成功"
+// @Failure 404 {string} string "文章不存在"
+// @Router /posts/{id}/like [delete]
+func (e EngagementController) Unlike(ctx *gin.Context) {
+	e.toggle(ctx, newPostLike, "like_count", false, "like_removed")
+}
+
+// Bookmark 收藏文章模块
+// @Summary 收藏文章接口
+// @Schemes
+// @Description 收藏文章，重复收藏不会重复计数
+// @Tags 文章收藏
+// @Accept application/json
+// @Produce application/json
+// @Param Authorization header string false "Bearer 用户令牌"
+// @Param id path string true "文章ID"
+// @Success 200 {string} string "收藏成功"
+// @Failure 404 {string} string "文章不存在"
+// @Router /posts/{id}/bookmark [put]
+func (e EngagementController) Bookmark(ctx *gin.Context) {
+	e.toggle(ctx, newPostBookmark, "bookmark_count", true, "bookmark_added")
+}
+
+// Unbookmark 取消收藏模块
+// @Summary 取消收藏接口
+// @Schemes
+// @Description 取消收藏文章，未收藏时直接返回成功
+// @Tags 文章收藏
+// @Accept application/json
+// @Produce application/json
+// @Param Authorization header string false "Bearer 用户令牌"
+// @Param id path string true "文章ID"
+// @Success 200 {string} string "取消收藏成功"
+// @Failure 404 {string} string "文章不存在"
+// @Router /posts/{id}/bookmark [delete]
+func (e EngagementController) Unbookmark(ctx *gin.Context) {
+	e.toggle(ctx, newPostBookmark, "bookmark_count", false, "bookmark_removed")
+}
+
+// Bookmarks 我的收藏模块
+// @Summary 我的收藏接口
+// @Schemes
+// @Description 分页列出当前用户收藏的文章，按收藏时间倒序；已转为他人草稿或已移入回收站的文章不列出
+// @Tags 文章收藏
+// @Accept application/json
+// @Produce application/json
+// @Param Authorization header string false "Bearer 用户令牌"
+// @Param pageNum query integer false "页码"
+// @Param pageSize query integer false "每页条数"
+// @Success 200 {string} string "成功"
+// @Router /posts/bookmarks [get]
+func (e EngagementController) Bookmarks(ctx *gin.Context) {
+	db := e.DB.WithContext(ctx.Request.Context())
+	pageNum, _ := strconv.Atoi(ctx.DefaultQuery("pageNum", "1"))
+	pageSize, _ := strconv.Atoi(ctx.DefaultQuery("pageSize", "20"))
+
+	// 关联文章过滤，收藏后文章转为草稿或移入回收站时不再列出，列表与总数一致
+	user, _ := ctx.Get("user")
+	uid := user.(model.User).ID
+	query := db.Model(model.PostBookmark{}).
+		Joins("JOIN posts ON posts.id = post_bookmarks.post_id AND posts.deleted_at IS NULL").
+		Where("post_bookmarks.user_id = ?", uid).
+		Where("posts.status = ? OR posts.user_id = ?", model.PostStatusPublished, uid)
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		ctx.Error(errcode.Wrap(err, errcode.Internal))
+		return
+	}
+	var bookmarks []model.PostBookmark
+	err := query.Preload("Post").Order("post_bookmarks.created_at desc").Offset((pageNum - 1) * pageSize).Limit(pageSize).Find(&bookmarks).Error
+	if err != nil {
+		ctx.Error(errcode.Wrap(err, errcode.Internal))
+		return
+	}
+
+	response.Success(ctx, gin.H{"data": bookmarks, "total": total}, "success")
+}
+
+func newPostLike(userID uint, postID uuid.UUID) interface{} {
+	return &model.PostLike{UserID: userID, PostID: postID}
+}
+
+func newPostBookmark(userID uint, postID uuid.UUID) interface{} {
+	return &model.PostBookmark{UserID: userID, PostID: postID}
+}
+
+// toggle 幂等地新增或删除点赞/收藏记录，仅在记录实际变化时调整文章计数
+func (e EngagementController) toggle(ctx *gin.Context, newRecord func(uint, uuid.UUID) interface{}, counterColumn string, add bool, msg string) {
+	db := e.DB.WithContext(ctx.Request.Context())
+	postID := ctx.Params.ByName("id")
+	var post model.Post
+	if err := db.Scopes(visiblePostScope(ctx)).Where("id = ?", postID).First(&post).Error; err != nil {
+		ctx.Error(errcode.New(errcode.PostNotFound))
+		return
+	}
+
+	user, _ := ctx.Get("user")
+	record := newRecord(user.(model.User).ID, post.ID)
+
+	err := db.Transaction(func(tx *gorm.DB) error {
+		var result *gorm.DB
+		if add {
+			result = tx.Clauses(clause.OnConflict{DoNothing: true}).Create(record)
+		} else {
+			result = tx.Delete(record)
+		}
+		if result.Error != nil || result.RowsAffected == 0 {
+			return result.Error
+		}
+
+		delta := 1
+		if !add {
+			delta = -1
+		}
+		return tx.Model(&post).UpdateColumn(counterColumn, gorm.Expr(counterColumn+" + ?", delta)).Error
+	})
+	if err != nil {
+		ctx.Error(errcode.Wrap(err, errcode.OperationFailed))
+		return
+	}
+
+	db.Where("id = ?", post.ID).First(&post)
+	response.Success(ctx, gin.H{"like_count": post.LikeCount, "bookmark_count": post.BookmarkCount}, msg)
+}
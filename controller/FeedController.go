@@ -0,0 +1,260 @@
+package controller
+
+import (
+	"crypto/sha1"
+	"encoding/hex"
+	"fmt"
+	"gin-swagger/dao"
+	"gin-swagger/errcode"
+	"gin-swagger/model"
+	"gin-swagger/util"
+	"github.com/gin-gonic/gin"
+	"github.com/gorilla/feeds"
+	"github.com/spf13/viper"
+	"gorm.io/gorm"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// 订阅格式对应的响应类型
+var feedContentTypes = map[string]string{
+	"rss":  "application/rss+xml; charset=utf-8",
+	"atom": "application/atom+xml; charset=utf-8",
+	"json": "application/feed+json; charset=utf-8",
+}
+
+type IFeedController interface {
+	Site(ctx *gin.Context)
+	Category(ctx *gin.Context)
+	Author(ctx *gin.Context)
+}
+
+type FeedController struct {
+	DB *gorm.DB
+}
+
+func NewFeedController() IFeedController {
+	return FeedController{DB: dao.GetDB()}
+}
+
+// feedQuery 订阅源的筛选条件和描述
+type feedQuery struct {
+	key         string
+	title       string
+	link        string
+	description string
+	scope       func(db *gorm.DB) *gorm.DB
+}
+
+// Site 全站订阅模块
+// @Summary 全站订阅接口
+// @Schemes
+// @Description 输出全站已发布文章的订阅源，format 取 rss、atom 或 json
+// @Tags 文章订阅
+// @Produce application/rss+xml,application/atom+xml,application/feed+json
+// @Param format path string true "订阅格式 rss/atom/json"
+// @Param limit query integer false "条目数量"
+// @Success 200 {string} string "订阅内容"
+// @Success 304 {string} string "未修改"
+// @Failure 404 {string} string "不支持的订阅格式"
+// @Router /feeds/{format} [get]
+func (f FeedController) Site(ctx *gin.Context) {
+	f.render(ctx, feedQuery{
+		key:         "site",
+		title:       viper.GetString("site.title"),
+		link:        util.SiteURL("/"),
+		description: viper.GetString("site.description"),
+		scope:       func(db *gorm.DB) *gorm.DB { return db },
+	})
+}
+
+// Category 分类订阅模块
+// @Summary 分类订阅接口
+// @Schemes
+// @Description 输出指定分类下已发布文章的订阅源
+// @Tags 文章订阅
+// @Produce application/rss+xml,application/atom+xml,application/feed+json
+// @Param id path integer true "分类ID"
+// @Param format path string true "订阅格式 rss/atom/json"
+// @Param limit query integer false "条目数量"
+// @Success 200 {string} string "订阅内容"
+// @Success 304 {string} string "未修改"
+// @Failure 404 {string} string "分类不存在"
+// @Router /feeds/categories/{id}/{format} [get]
+func (f FeedController) Category(ctx *gin.Context) {
+	db := f.DB.WithContext(ctx.Request.Context())
+	categoryID, _ := strconv.Atoi(ctx.Params.ByName("id"))
+	var category model.Category
+	if err := db.First(&category, categoryID).Error; err != nil {
+		ctx.Error(errcode.New(errcode.CategoryNotFound))
+		return
+	}
+
+	f.render(ctx, feedQuery{
+		key:         "category:" + strconv.Itoa(int(category.ID)),
+		title:       viper.GetString("site.title") + " - " + category.Name,
+		link:        util.SiteURL(fmt.Sprintf("/categories/%d", category.ID)),
+		description: viper.GetString("site.description"),
+		scope: func(db *gorm.DB) *gorm.DB {
+			return db.Where("category_id = ?", category.ID)
+		},
+	})
+}
+
+// Author 作者订阅模块
+// @Summary 作者订阅接口
+// @Schemes
+// @Description 输出指定作者已发布文章的订阅源
+// @Tags 文章订阅
+// @Produce application/rss+xml,application/atom+xml,application/feed+json
+// @Param id path integer true "作者ID"
+// @Param format path string true "订阅格式 rss/atom/json"
+// @Param limit query integer false "条目数量"
+// @Success 200 {string} string "订阅内容"
+// @Success 304 {string} string "未修改"
+// @Failure 404 {string} string "作者不存在"
+// @Router /feeds/users/{id}/{format} [get]
+func (f FeedController) Author(ctx *gin.Context) {
+	db := f.DB.WithContext(ctx.Request.Context())
+	userID, _ := strconv.Atoi(ctx.Params.ByName("id"))
+	var user model.User
+	if err := db.First(&user, userID).Error; err != nil {
+		ctx.Error(errcode.New(errcode.AuthorNotFound))
+		return
+	}
+
+	f.render(ctx, feedQuery{
+		key:         "user:" + strconv.Itoa(int(user.ID)),
+		title:       viper.GetString("site.title") + " - " + user.Name,
+		link:        util.SiteURL(fmt.Sprintf("/users/%d", user.ID)),
+		description: viper.GetString("site.description"),
+		scope: func(db *gorm.DB) *gorm.DB {
+			return db.Where("user_id = ?", user.ID)
+		},
+	})
+}
+
+// render 生成订阅内容，未变化时根据 ETag/Last-Modified 返回 304
+func (f FeedController) render(ctx *gin.Context, q feedQuery) {
+	db := f.DB.WithContext(ctx.Request.Context())
+	format := ctx.Params.ByName("format")
+	contentType, ok := feedContentTypes[format]
+	if !ok {
+		ctx.Error(errcode.New(errcode.FeedNotFound))
+		return
+	}
+	limit := feedLimit(ctx)
+
+	// 用最新更新时间和文章数生成缓存校验值，避免未变化时查询全部条目
+	published := func() *gorm.DB {
+		return q.scope(db.Model(model.Post{}).Where("status = ?", model.PostStatusPublished))
+	}
+	var total int64
+	if err := published().Count(&total).Error; err != nil {
+		ctx.Error(errcode.Wrap(err, errcode.Internal))
+		return
+	}
+	lastModified := time.Unix(0, 0).UTC()
+	var latest model.Post
+	if total > 0 && published().Select("updated_at").Order("updated_at desc").Take(&latest).Error == nil {
+		lastModified = time.Time(latest.UpdatedAt).UTC().Truncate(time.Second)
+	}
+	sum := sha1.Sum([]byte(fmt.Sprintf("%s|%s|%d|%d|%d", q.key, format, limit, total, lastModified.Unix())))
+	etag := `W/"` + hex.EncodeToString(sum[:]) + `"`
+
+	ctx.Header("ETag", etag)
+	ctx.Header("Last-Modified", lastModified.Format(http.TimeFormat))
+	ctx.Header("Cache-Control", fmt.Sprintf("public, max-age=%d", viper.GetInt("feed.max_age")))
+	if notModified(ctx, etag, lastModified) {
+		ctx.Status(http.StatusNotModified)
+		return
+	}
+
+	var posts []model.Post
+	published().Order("created_at desc").Limit(limit).Find(&posts)
+	feed := f.build(ctx, q, posts, lastModified)
+
+	var body string
+	var err error
+	switch format {
+	case "rss":
+		body, err = feed.ToRss()
+	case "atom":
+		body, err = feed.ToAtom()
+	case "json":
+		body, err = feed.ToJSON()
+	}
+	if err != nil {
+		ctx.Error(errcode.Wrap(err, errcode.Internal))
+		return
+	}
+
+	ctx.Data(http.StatusOK, contentType, []byte(body))
+}
+
+// build 将文章转换为订阅条目
+func (f FeedController) build(ctx *gin.Context, q feedQuery, posts []model.Post, updated time.Time) *feeds.Feed {
+	db := f.DB.WithContext(ctx.Request.Context())
+	// 批量查询作者名称
+	userIDs := make([]uint, 0, len(posts))
+	for _, post := range posts {
+		userIDs = append(userIDs, post.UserID)
+	}
+	var users []model.User
+	if len(userIDs) > 0 {
+		db.Select("id", "name").Where("id IN ?", userIDs).Find(&users)
+	}
+	names := make(map[uint]string, len(users))
+	for _, user := range users {
+		names[user.ID] = user.Name
+	}
+
+	feed := &feeds.Feed{
+		Title:       q.title,
+		Link:        &feeds.Link{Href: q.link},
+		Description: q.description,
+		Id:          q.link,
+		Updated:     updated,
+	}
+	for _, post := range posts {
+		link := util.SiteURL("/posts/" + post.ID.String())
+		feed.Add(&feeds.Item{
+			Id:          link,
+			Title:       post.Title,
+			Link:        &feeds.Link{Href: link},
+			Author:      &feeds.Author{Name: names[post.UserID]},
+			Description: post.Excerpt,
+			Content:     post.ContentHTML,
+			Created:     time.Time(post.CreatedAt),
+			Updated:     time.Time(post.UpdatedAt),
+		})
+	}
+	return feed
+}
+
+// feedLimit 读取条目数量，超出上限时按上限处理
+func feedLimit(ctx *gin.Context) int {
+	limit := viper.GetInt("feed.limit")
+	if limit <= 0 {
+		limit = 20
+	}
+	if value, err := strconv.Atoi(ctx.Query("limit")); err == nil && value > 0 {
+		limit = value
+	}
+	if maxLimit := viper.GetInt("feed.max_limit"); maxLimit > 0 && limit > maxLimit {
+		limit = maxLimit
+	}
+	return limit
+}
+
+// notModified 按 If-None-Match 优先、If-Modified-Since 其次判断客户端缓存是否有效
+func notModified(ctx *gin.Context, etag string, lastModified time.Time) bool {
+	if match := ctx.GetHeader("If-None-Match"); match != "" {
+		return match == etag || match == "*"
+	}
+	if since, err := http.ParseTime(ctx.GetHeader("If-Modified-Since")); err == nil {
+		return !lastModified.After(since)
+	}
+	return false
+}
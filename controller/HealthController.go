@@ -0,0 +1,48 @@
+package controller
+
+import (
+	"gin-swagger/health"
+	"github.com/gin-gonic/gin"
+	"github.com/spf13/viper"
+	"net/http"
+	"time"
+)
+
+const defaultHealthTimeout = 2 * time.Second
+
+// Healthz 存活检查模块
+// @Summary 存活检查接口
+// @Schemes
+// @Description 进程存活即返回 200，不检查依赖
+// @Tags 健康检查
+// @Produce application/json
+// @Success 200 {string} string "ok"
+// @Router /healthz [get]
+func Healthz(ctx *gin.Context) {
+	ctx.Header("Cache-Control", "no-store")
+	ctx.JSON(http.StatusOK, gin.H{"status": health.StatusOK})
+}
+
+// Readyz 就绪检查模块
+// @Summary 就绪检查接口
+// @Schemes
+// @Description 并发检查数据库等依赖，返回每项的状态和耗时；任一项不可用或服务正在关闭时返回 503
+// @Tags 健康检查
+// @Produce application/json
+// @Success 200 {object} health.Report "全部就绪"
+// @Failure 503 {object} health.Report "未就绪"
+// @Router /readyz [get]
+func Readyz(ctx *gin.Context) {
+	timeout := viper.GetDuration("health.timeout")
+	if timeout <= 0 {
+		timeout = defaultHealthTimeout
+	}
+
+	report := health.Check(ctx.Request.Context(), timeout)
+	status := http.StatusOK
+	if report.Status != health.StatusOK {
+		status = http.StatusServiceUnavailable
+	}
+	ctx.Header("Cache-Control", "no-store")
+	ctx.JSON(status, report)
+}
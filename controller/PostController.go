@@ -1,23 +1,35 @@
 package controller
 
 import (
+	"gin-swagger/counter"
 	"gin-swagger/dao"
+	"gin-swagger/errcode"
 	"gin-swagger/dto"
+	"gin-swagger/fieldset"
+	"gin-swagger/logger"
+	"gin-swagger/metrics"
 	"gin-swagger/model"
 	"gin-swagger/response"
+	"gin-swagger/search"
+	"gin-swagger/sitemap"
 	"github.com/gin-gonic/gin"
 	"gorm.io/gorm"
-	"log"
 	"strconv"
+	"strings"
 )
 
 type IPostController interface {
 	RestController
 	PageList(ctx *gin.Context)
+	Search(ctx *gin.Context)
+	CommentSetting(ctx *gin.Context)
 }
 
 type PostController struct {
-	DB *gorm.DB
+	DB          *gorm.DB
+	Searcher    search.Searcher
+	ViewCounter *counter.ViewCounter
+	Sitemap     *sitemap.Generator
 }
 
 // Create 创建文章模块
@@ -29,14 +41,16 @@ type PostController struct {
 // @Produce application/json
 // @Param Authorization header string false "Bearer 用户令牌"
 // @Param object query dto.CreatePostRequest false "创建参数"
+// @Param Idempotency-Key header string false "幂等键，重试时使用相同的值"
 // @Success 200 {string} string "创建成功"
 // @Failure 400 {string} string "数据验证错误"
 // @Router /posts [post]
 func (p PostController) Create(ctx *gin.Context) {
+	db := p.DB.WithContext(ctx.Request.Context())
 	var requestPost dto.CreatePostRequest
 	// 数据验证
 	if err := ctx.ShouldBind(&requestPost); err != nil {
-		response.Fail(ctx, nil,"数据验证错误")
+		ctx.Error(errcode.Invalid(err))
 		return
 	}
 
@@ -50,15 +64,32 @@ func (p PostController) Create(ctx *gin.Context) {
 		Title: requestPost.Title,
 		HeadImg: requestPost.HeadImg,
 		Content: requestPost.Content,
+		Status: requestPost.Status,
+		CommentsDisabled: requestPost.CommentsDisabled,
+	}
+	if post.Status == "" {
+		post.Status = model.PostStatusPublished
+	}
+
+	// 渲染 Markdown 正文
+	if err := post.RenderContent(); err != nil {
+		ctx.Error(errcode.Wrap(err, errcode.PostRenderFailed))
+		return
 	}
 
 	// 插入数据
-	if err := p.DB.Create(&post).Error; err != nil {
-		log.Println(err)
+	if err := db.Create(&post).Error; err != nil {
+		ctx.Error(errcode.Wrap(err, errcode.Internal))
 		return
 	}
+	metrics.PostsCreated.Inc()
 
-	response.Success(ctx, nil, "创建文章成功")
+	// 同步检索索引
+	if err := p.Searcher.Index(post); err != nil {
+		logger.FromContext(ctx).Error("index post failed", "post_id", post.ID, "error", err)
+	}
+
+	response.Success(ctx, nil, "post_created")
 }
 
 // Update 更新文章模块
@@ -72,14 +103,15 @@ func (p PostController) Create(ctx *gin.Context) {
 // @Param id path integer true "文章ID"
 // @Param object query dto.CreatePostRequest false "查询参数"
 // @Success 200 {string} string "修改成功"
-// @Failure 400 {string} string "文章不存在"
+// @Failure 404 {string} string "文章不存在"
 // @Router /posts/{id} [put]
 func (p PostController) Update(ctx *gin.Context) {
+	db := p.DB.WithContext(ctx.Request.Context())
 	var requestPost dto.CreatePostRequest
 	// 数据验证
 	if err := ctx.ShouldBind(&requestPost); err != nil {
-		log.Println(err)
-		response.Fail(ctx, nil,"数据验证错误")
+		logger.FromContext(ctx).Debug("invalid post request", "error", err)
+		ctx.Error(errcode.Invalid(err))
 		return
 	}
 
@@ -88,8 +120,8 @@ func (p PostController) Update(ctx *gin.Context) {
 	postID := ctx.Params.ByName("id")
 
 	var post model.Post
-	if err := p.DB.Where("id = ?", postID).First(&post).Error; err !=nil {
-		response.Fail(ctx, nil,"文章不存在")
+	if err := db.Where("id = ?", postID).First(&post).Error; err !=nil {
+		ctx.Error(errcode.New(errcode.PostNotFound))
 		return
 	}
 
@@ -98,55 +130,109 @@ func (p PostController) Update(ctx *gin.Context) {
 	user, _ := ctx.Get("user")
 	userID := user.(model.User).ID
 	if userID != post.UserID {
-		response.Fail(ctx, nil,"非文章作者，请勿操作")
+		ctx.Error(errcode.New(errcode.NotPostAuthor))
+		return
+	}
+
+	// 渲染 Markdown 正文
+	updatePost := model.Post{
+		CategoryID: requestPost.CategoryID,
+		Title: requestPost.Title,
+		HeadImg: requestPost.HeadImg,
+		Content: requestPost.Content,
+		Status: requestPost.Status,
+	}
+	if err := updatePost.RenderContent(); err != nil {
+		ctx.Error(errcode.Wrap(err, errcode.PostRenderFailed))
 		return
 	}
 
-	// 更新文章
-	err := p.DB.Model(&post).
-		Updates(model.Post{
-			CategoryID: requestPost.CategoryID,
-			Title: requestPost.Title,
-			HeadImg: requestPost.HeadImg,
-			Content: requestPost.Content,
-		}).Error
+	// 更新文章，摘要和目录可能变为空值，需显式指定更新列
+	columns := []string{"category_id", "title", "content", "content_html", "excerpt", "toc"}
+	if updatePost.HeadImg != "" {
+		columns = append(columns, "head_img")
+	}
+	if updatePost.Status != "" {
+		columns = append(columns, "status")
+	}
+	err := db.Model(&post).Select(columns).Updates(updatePost).Error
 	if  err != nil {
-		response.Fail(ctx, nil,"文章更新失败")
+		ctx.Error(errcode.Wrap(err, errcode.PostUpdateFailed))
 		return
 	}
 
-	response.Success(ctx, gin.H{"post": post}, "更新成功")
+	// 同步检索索引
+	if err := p.Searcher.Index(post); err != nil {
+		logger.FromContext(ctx).Error("index post failed", "post_id", post.ID, "error", err)
+	}
+
+	response.Success(ctx, gin.H{"post": post}, "post_updated")
 }
 
 // Show 查看文章模块
 // @Summary 查看文章接口
 // @Schemes
-// @Description 查看文章模块
+// @Description 查看文章模块，返回 Markdown 原文、过滤后的 HTML、纯文本摘要和目录
 // @Tags 查看文章
 // @Accept application/json
 // @Produce application/json
 // @Param Authorization header string false "Bearer 用户令牌"
 // @Param id path integer true "文章ID"
+// @Param fields query string false "返回的字段，逗号分隔"
+// @Param expand query string false "展开的关联，可选 category、author、tags"
 // @Success 200 {string} string "查看成功"
-// @Failure 400 {string} string "文章不存在"
+// @Failure 404 {string} string "文章不存在"
 // @Router /posts/{id} [get]
 func (p PostController) Show(ctx *gin.Context) {
+	db := p.DB.WithContext(ctx.Request.Context())
+	selection, err := postResource.Parse(ctx)
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+
 	// 获取path 中的id
 	postID := ctx.Params.ByName("id")
 
 	var post model.Post
-	if err := p.DB.Preload("Category").Where("id = ?", postID).First(&post).Error; err !=nil {
-		response.Fail(ctx, nil,"文章不存在")
+	if err := db.Scopes(preloadScope(selection)).Where("id = ?", postID).First(&post).Error; err !=nil {
+		ctx.Error(errcode.New(errcode.PostNotFound))
 		return
 	}
 
-	response.Success(ctx, gin.H{"post": post}, "查看文章成功")
+	// 草稿仅作者可见
+	user, _ := ctx.Get("user")
+	if post.Status == model.PostStatusDraft && user.(model.User).ID != post.UserID {
+		ctx.Error(errcode.New(errcode.PostNotFound))
+		return
+	}
+
+	// 旧数据没有渲染结果时补充渲染并回写
+	if post.ContentHTML == "" && post.Content != "" {
+		if err := post.RenderContent(); err == nil {
+			db.Model(&post).UpdateColumns(model.Post{ContentHTML: post.ContentHTML, Excerpt: post.Excerpt, TOC: post.TOC})
+		}
+	}
+
+	// 浏览计数，登陆用户按用户去重，否则按IP去重
+	viewer := "ip:" + ctx.ClientIP()
+	if user != nil {
+		viewer = "user:" + strconv.Itoa(int(user.(model.User).ID))
+	}
+	p.ViewCounter.Hit(post.ID.String(), viewer)
+
+	data, err := p.present(ctx, selection, post)
+	if err != nil {
+		ctx.Error(errcode.Wrap(err, errcode.Internal))
+		return
+	}
+	response.Success(ctx, gin.H{"post": data[0]}, "post_shown")
 }
 
 // Delete 删除文章模块
 // @Summary 删除文章接口
 // @Schemes
-// @Description 删除文章模块
+// @Description 删除文章模块，文章移入回收站，可在保留期内恢复
 // @Tags 删除文章
 // @Accept application/json
 // @Produce application/json
@@ -156,12 +242,13 @@ func (p PostController) Show(ctx *gin.Context) {
 // @Failure 400 {string} string "删除失败"
 // @Router /posts/{id} [delete]
 func (p PostController) Delete(ctx *gin.Context) {
+	db := p.DB.WithContext(ctx.Request.Context())
 	// 获取path 中的id
 	postID := ctx.Params.ByName("id")
 
 	var post model.Post
-	if err := p.DB.Where("id = ?", postID).First(&post).Error; err !=nil {
-		response.Fail(ctx, nil,"文章不存在")
+	if err := db.Where("id = ?", postID).First(&post).Error; err !=nil {
+		ctx.Error(errcode.New(errcode.PostNotFound))
 		return
 	}
 
@@ -170,15 +257,21 @@ func (p PostController) Delete(ctx *gin.Context) {
 	user, _ := ctx.Get("user")
 	userID := user.(model.User).ID
 	if userID != post.UserID {
-		response.Fail(ctx, nil,"非文章作者，请勿操作")
+		ctx.Error(errcode.New(errcode.NotPostAuthor))
 		return
 	}
 
-	if err := p.DB.Delete(&post).Error; err != nil {
-		response.Fail(ctx, nil,"文章删除失败")
+	if err := db.Delete(&post).Error; err != nil {
+		ctx.Error(errcode.Wrap(err, errcode.PostDeleteFailed))
 		return
 	}
-	response.Success(ctx, nil, "删除文章成功")
+
+	// 同步检索索引和站点地图
+	if err := p.Searcher.Delete(post.ID.String()); err != nil {
+		logger.FromContext(ctx).Error("delete post from index failed", "post_id", post.ID, "error", err)
+	}
+	p.Sitemap.Remove(post.ID.String())
+	response.Success(ctx, nil, "post_trashed")
 }
 
 // PageList 列出文章模块
@@ -190,27 +283,247 @@ func (p PostController) Delete(ctx *gin.Context) {
 // @Produce application/json
 // @Param Authorization header string false "Bearer 用户令牌"
 // @Param object query model.Post false "查询参数"
+// @Param fields query string false "返回的字段，逗号分隔"
+// @Param expand query string false "展开的关联，可选 category、author、tags"
 // @Success 200 {string} string "成功"
 // @Failure 400 {string} string "失败"
 // @Router /posts/{id} [delete]
 func (p PostController) PageList(ctx *gin.Context) {
+	db := p.DB.WithContext(ctx.Request.Context())
+	selection, err := postResource.Parse(ctx)
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+
 	// 获取分页参数
 	pageNum, _ := strconv.Atoi(ctx.DefaultQuery("pageNum","1"))
 	pageSize, _ := strconv.Atoi(ctx.DefaultQuery("pageSize","20"))
 
-	// 分页
+	// 分页，选择了字段时只查询所需的列，关联的外键始终查询
+	query := db.Scopes(postListScope(ctx), preloadScope(selection))
+	if columns := selection.Columns("id", "user_id", "category_id"); columns != nil {
+		query = query.Select(columns)
+	}
 	var posts []model.Post
-	p.DB.Order("created_at desc").Offset((pageNum - 1) * pageSize).Limit(pageSize).Find(&posts);
+	if err := query.Order("created_at desc").Offset((pageNum - 1) * pageSize).Limit(pageSize).Find(&posts).Error; err != nil {
+		ctx.Error(errcode.Wrap(err, errcode.Internal))
+		return
+	}
 
 	// 前端渲染分页需要知道总数
 	var total int64
-	p.DB.Model(model.Post{}).Count(&total)
+	if err := db.Model(model.Post{}).Scopes(postListScope(ctx)).Count(&total).Error; err != nil {
+		ctx.Error(errcode.Wrap(err, errcode.Internal))
+		return
+	}
+
+	data, err := p.present(ctx, selection, posts...)
+	if err != nil {
+		ctx.Error(errcode.Wrap(err, errcode.Internal))
+		return
+	}
+	response.Success(ctx, gin.H{"data": data, "total": total}, "success")
+}
+
+// postResource 文章可选择的字段和可展开的关联，字段名与数据库列名一致
+var postResource = fieldset.Resource{
+	Fields: []string{"id", "user_id", "category_id", "title", "head_img", "content", "content_html", "excerpt", "toc",
+		"status", "comments_disabled", "like_count", "bookmark_count", "view_count", "created_at", "updated_at"},
+	Expands: []string{"category", "author", "tags"},
+}
+
+// preloadScope 预加载需要展开的关联，作者单独查询以免返回敏感信息
+func preloadScope(selection fieldset.Selection) func(db *gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		if selection.Expanded("category") {
+			db = db.Preload("Category")
+		}
+		if selection.Expanded("tags") {
+			db = db.Preload("Tags")
+		}
+		return db
+	}
+}
+
+// present 按选择的字段裁剪文章，并附上展开的作者
+func (p PostController) present(ctx *gin.Context, selection fieldset.Selection, posts ...model.Post) ([]gin.H, error) {
+	db := p.DB.WithContext(ctx.Request.Context())
+	authors := map[uint]dto.AuthorDto{}
+	if selection.Expanded("author") && len(posts) > 0 {
+		userIDs := make([]uint, 0, len(posts))
+		for _, post := range posts {
+			userIDs = append(userIDs, post.UserID)
+		}
+		var users []model.User
+		if err := db.Where("id IN ?", userIDs).Find(&users).Error; err != nil {
+			return nil, err
+		}
+		for _, user := range users {
+			authors[user.ID] = dto.ToAuthorDto(user)
+		}
+	}
+
+	data := make([]gin.H, 0, len(posts))
+	for _, post := range posts {
+		item, err := selection.Apply(post)
+		if err != nil {
+			return nil, err
+		}
+		if selection.Expanded("tags") && item["tags"] == nil {
+			item["tags"] = []model.Tag{}
+		}
+		if author, ok := authors[post.UserID]; ok {
+			item["author"] = author
+		}
+		data = append(data, item)
+	}
+	return data, nil
+}
+
+// visiblePostScope 当前用户可见的文章：已发布的文章和自己的草稿
+func visiblePostScope(ctx *gin.Context) func(db *gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		user, _ := ctx.Get("user")
+		return db.Where("status = ? OR user_id = ?", model.PostStatusPublished, user.(model.User).ID)
+	}
+}
+
+// postListScope 文章列表的筛选条件，支持按分类、作者和状态筛选，草稿仅作者可见
+func postListScope(ctx *gin.Context) func(db *gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		db = db.Scopes(visiblePostScope(ctx))
+
+		if categoryID, err := strconv.Atoi(ctx.Query("category_id")); err == nil {
+			db = db.Where("category_id = ?", categoryID)
+		}
+		if userID, err := strconv.Atoi(ctx.Query("user_id")); err == nil {
+			db = db.Where("user_id = ?", userID)
+		}
+		if status := ctx.Query("status"); status != "" {
+			db = db.Where("status = ?", status)
+		}
+		return db
+	}
+}
+
+// CommentSetting 文章评论设置模块
+// @Summary 文章评论设置接口
+// @Schemes
+// @Description 文章作者开启或关闭文章评论
+// @Tags 文章评论设置
+// @Accept application/json
+// @Produce application/json
+// @Param Authorization header string false "Bearer 用户令牌"
+// @Param id path string true "文章ID"
+// @Param object query dto.CommentSettingRequest false "设置参数"
+// @Success 200 {string} string "设置成功"
+// @Failure 404 {string} string "文章不存在"
+// @Router /posts/{id}/comments/setting [put]
+func (p PostController) CommentSetting(ctx *gin.Context) {
+	db := p.DB.WithContext(ctx.Request.Context())
+	var requestSetting dto.CommentSettingRequest
+	if err := ctx.ShouldBind(&requestSetting); err != nil {
+		ctx.Error(errcode.Invalid(err))
+		return
+	}
+
+	// 获取path 中的id
+	postID := ctx.Params.ByName("id")
+
+	var post model.Post
+	if err := db.Where("id = ?", postID).First(&post).Error; err !=nil {
+		ctx.Error(errcode.New(errcode.PostNotFound))
+		return
+	}
+
+	// 判断当前用户是否为文章作者
+	user, _ := ctx.Get("user")
+	if user.(model.User).ID != post.UserID {
+		ctx.Error(errcode.New(errcode.NotPostAuthor))
+		return
+	}
+
+	if err := db.Model(&post).Update("comments_disabled", *requestSetting.Disabled).Error; err != nil {
+		ctx.Error(errcode.Wrap(err, errcode.PostSettingFailed))
+		return
+	}
+
+	response.Success(ctx, gin.H{"post": post}, "post_setting_updated")
+}
+
+// Search 检索文章模块
+// @Summary 检索文章接口
+// @Schemes
+// @Description 按标题和正文全文检索文章，结果按相关度排序并返回高亮片段
+// @Tags 检索文章
+// @Accept application/json
+// @Produce application/json
+// @Param Authorization header string false "Bearer 用户令牌"
+// @Param q query string true "关键字"
+// @Param pageNum query integer false "页码"
+// @Param pageSize query integer false "每页条数"
+// @Success 200 {string} string "成功"
+// @Failure 400 {string} string "关键字不能为空"
+// @Router /posts/search [get]
+func (p PostController) Search(ctx *gin.Context) {
+	db := p.DB.WithContext(ctx.Request.Context())
+	keyword := strings.TrimSpace(ctx.Query("q"))
+	if keyword == "" {
+		ctx.Error(errcode.New(errcode.KeywordRequired))
+		return
+	}
+
+	// 获取分页参数
+	pageNum, _ := strconv.Atoi(ctx.DefaultQuery("pageNum","1"))
+	pageSize, _ := strconv.Atoi(ctx.DefaultQuery("pageSize","20"))
+	if pageNum < 1 {
+		pageNum = 1
+	}
+	if pageSize < 1 || pageSize > 100 {
+		pageSize = 20
+	}
+
+	user, _ := ctx.Get("user")
+	result, err := p.Searcher.Search(keyword, user.(model.User).ID, (pageNum - 1) * pageSize, pageSize)
+	if err != nil {
+		ctx.Error(errcode.Wrap(err, errcode.SearchFailed))
+		return
+	}
+
+	// 按检索结果的相关度顺序回填文章
+	ids := make([]string, 0, len(result.Hits))
+	for _, hit := range result.Hits {
+		ids = append(ids, hit.ID)
+	}
+	// 数据库中再按可见性过滤一次，防止索引未及时更新时返回不可见的文章
+	var posts []model.Post
+	if err := db.Scopes(visiblePostScope(ctx)).Where("id IN ?", ids).Find(&posts).Error; err != nil {
+		ctx.Error(errcode.Wrap(err, errcode.SearchFailed))
+		return
+	}
+	postMap := make(map[string]model.Post, len(posts))
+	for _, post := range posts {
+		postMap[post.ID.String()] = post
+	}
+
+	data := make([]gin.H, 0, len(result.Hits))
+	total := result.Total
+	for _, hit := range result.Hits {
+		post, ok := postMap[hit.ID]
+		if !ok {
+			// 索引中残留的文章不计入总数
+			total--
+			continue
+		}
+		data = append(data, gin.H{"post": post, "score": hit.Score, "highlights": hit.Highlights})
+	}
 
-	response.Success(ctx, gin.H{"data": posts, "total": total}, "成功")
+	response.Success(ctx, gin.H{"data": data, "total": total}, "success")
 }
 
 func NewPostController() IPostController {
 	db := dao.GetDB()
-	db.AutoMigrate(model.Post{})
-	return PostController{DB: db}
+	db.AutoMigrate(model.Tag{}, model.Post{})
+	return PostController{DB: db, Searcher: search.GetSearcher(), ViewCounter: counter.GetViewCounter(), Sitemap: sitemap.GetGenerator()}
 }
\ No newline at end of file
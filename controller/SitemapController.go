@@ -0,0 +1,93 @@
+package controller
+
+import (
+	"fmt"
+	"gin-swagger/errcode"
+	"gin-swagger/sitemap"
+	"github.com/gin-gonic/gin"
+	"net/http"
+	"strings"
+)
+
+type ISitemapController interface {
+	Sitemap(ctx *gin.Context)
+	Part(ctx *gin.Context)
+}
+
+type SitemapController struct {
+	Generator *sitemap.Generator
+}
+
+func NewSitemapController() ISitemapController {
+	return SitemapController{Generator: sitemap.GetGenerator()}
+}
+
+// Sitemap 站点地图模块
+// @Summary 站点地图接口
+// @Schemes
+// @Description URL 数不超过 50000 时直接输出站点地图，否则输出站点地图索引；请求 /sitemap.xml.gz 时返回 gzip 压缩内容
+// @Tags 站点地图
+// @Produce application/xml
+// @Success 200 {string} string "站点地图"
+// @Router /sitemap.xml [get]
+func (s SitemapController) Sitemap(ctx *gin.Context) {
+	if !s.refresh(ctx) {
+		return
+	}
+	compressed := strings.HasSuffix(ctx.Request.URL.Path, ".gz")
+
+	if s.Generator.Total() > sitemap.MaxURLs {
+		s.write(ctx, s.Generator.Index(compressed), compressed)
+		return
+	}
+
+	body, _ := s.Generator.Sitemap(1, compressed)
+	s.write(ctx, body, compressed)
+}
+
+// Part 站点地图分片模块
+// @Summary 站点地图分片接口
+// @Schemes
+// @Description 输出站点地图索引中的分片，文件名形如 sitemap-1.xml 或 sitemap-1.xml.gz
+// @Tags 站点地图
+// @Produce application/xml
+// @Param file path string true "分片文件名"
+// @Success 200 {string} string "站点地图分片"
+// @Failure 404 {string} string "分片不存在"
+// @Router /sitemaps/{file} [get]
+func (s SitemapController) Part(ctx *gin.Context) {
+	file := ctx.Params.ByName("file")
+	compressed := strings.HasSuffix(file, ".gz")
+
+	var n int
+	if _, err := fmt.Sscanf(strings.TrimSuffix(file, ".gz"), "sitemap-%d.xml", &n); err != nil {
+		ctx.Error(errcode.New(errcode.SitemapShardNotFound))
+		return
+	}
+	if !s.refresh(ctx) {
+		return
+	}
+
+	body, ok := s.Generator.Sitemap(n, compressed)
+	if !ok {
+		ctx.Error(errcode.New(errcode.SitemapShardNotFound))
+		return
+	}
+	s.write(ctx, body, compressed)
+}
+
+func (s SitemapController) refresh(ctx *gin.Context) bool {
+	if err := s.Generator.Refresh(); err != nil {
+		ctx.Error(errcode.Wrap(err, errcode.Internal))
+		return false
+	}
+	return true
+}
+
+func (s SitemapController) write(ctx *gin.Context, body []byte, compressed bool) {
+	if compressed {
+		ctx.Data(http.StatusOK, "application/gzip", body)
+		return
+	}
+	ctx.Data(http.StatusOK, "application/xml; charset=utf-8", body)
+}
@@ -0,0 +1,229 @@
+package controller
+
+import (
+	"errors"
+	"gin-swagger/dao"
+	"gin-swagger/errcode"
+	"gin-swagger/i18n"
+	"gin-swagger/logger"
+	"gin-swagger/metrics"
+	"gin-swagger/model"
+	"gin-swagger/response"
+	"gin-swagger/search"
+	"gin-swagger/transfer"
+	"github.com/gin-gonic/gin"
+	"github.com/spf13/viper"
+	"gorm.io/gorm"
+	"io/ioutil"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+const defaultImportMaxSize = 10 << 20
+
+// importFormOverhead 导入请求体中文件以外的部分（multipart 分隔符、字段头等）允许的大小
+const importFormOverhead = 1 << 20
+
+// exportBatchSize 导出时每批查询的文章数
+const exportBatchSize = 100
+
+type ITransferController interface {
+	Export(ctx *gin.Context)
+	Import(ctx *gin.Context)
+}
+
+type TransferController struct {
+	DB       *gorm.DB
+	Searcher search.Searcher
+}
+
+func NewTransferController() ITransferController {
+	return TransferController{DB: dao.GetDB(), Searcher: search.GetSearcher()}
+}
+
+// Export 导出文章模块
+// @Summary 导出文章接口
+// @Schemes
+// @Description 按列表接口相同的过滤条件导出文章，支持 ndjson、csv 和 markdown（带 YAML front matter 的 zip 包）
+// @Tags 导出文章
+// @Produce application/x-ndjson
+// @Produce text/csv
+// @Produce application/zip
+// @Param Authorization header string false "Bearer 用户令牌"
+// @Param format query string false "导出格式 ndjson|csv|markdown，默认 ndjson"
+// @Param category_id query int false "分类ID"
+// @Param user_id query int false "作者ID"
+// @Param status query string false "文章状态 draft|published"
+// @Success 200 {string} string "导出文件"
+// @Failure 400 {string} string "不支持的导出格式"
+// @Router /posts/export [get]
+func (t TransferController) Export(ctx *gin.Context) {
+	db := t.DB.WithContext(ctx.Request.Context())
+	response.IgnoreFormatQuery(ctx)
+	format := fileFormat(ctx)
+	if format == "" {
+		format = transfer.FormatNDJSON
+	}
+	if err := transfer.ValidFormat(format); err != nil {
+		ctx.Error(errcode.New(errcode.UnsupportedFormat, format))
+		return
+	}
+
+	contentType, filename := transfer.ContentType(format)
+	ctx.Header("Content-Type", contentType)
+	ctx.Header("Content-Disposition", `attachment; filename="`+filename+`"`)
+	ctx.Status(http.StatusOK)
+
+	// 分批查询并边查边写，避免一次性加载全部文章
+	writer, err := transfer.NewWriter(format, ctx.Writer)
+	if err != nil {
+		logger.FromContext(ctx).Error("create export writer failed", "error", err)
+		return
+	}
+	if err := exportPosts(ctx, db, writer); err != nil {
+		// 响应头已发出，只能记录日志
+		logger.FromContext(ctx).Error("export posts failed", "error", err)
+	}
+	if err := writer.Close(); err != nil {
+		logger.FromContext(ctx).Error("close export writer failed", "error", err)
+	}
+}
+
+// fileFormat 导入导出的文件格式，取查询参数 format，也接受 file_format
+func fileFormat(ctx *gin.Context) string {
+	if format := ctx.Query("format"); format != "" {
+		return format
+	}
+	return ctx.Query("file_format")
+}
+
+// exportPosts 按创建时间倒序分批写出文章，以 (created_at, id) 作为游标翻页，
+// 文章ID是随机 UUID，不能像 FindInBatches 那样只按主键翻页
+func exportPosts(ctx *gin.Context, db *gorm.DB, writer transfer.Writer) error {
+	var last *model.Post
+	for {
+		query := db.Scopes(postListScope(ctx)).Preload("Category").Preload("Tags")
+		if last != nil {
+			query = query.Where("(created_at < ? OR (created_at = ? AND id < ?))", last.CreatedAt, last.CreatedAt, last.ID)
+		}
+		var posts []model.Post
+		if err := query.Order("created_at desc").Order("id desc").Limit(exportBatchSize).Find(&posts).Error; err != nil {
+			return err
+		}
+		for _, post := range posts {
+			if err := writer.Write(transfer.FromPost(post)); err != nil {
+				return err
+			}
+		}
+		if len(posts) < exportBatchSize {
+			return nil
+		}
+		last = &posts[len(posts)-1]
+	}
+}
+
+// Import 导入文章模块
+// @Summary 导入文章接口
+// @Schemes
+// @Description 上传 ndjson、csv 或 markdown zip 文件导入为当前用户的文章，分类按名称匹配，逐条校验并返回每条结果；dry_run 为 true 时只校验不写入
+// @Tags 导入文章
+// @Accept multipart/form-data
+// @Produce application/json
+// @Param Authorization header string false "Bearer 用户令牌"
+// @Param file formData file true "导入文件"
+// @Param format query string false "导入格式 ndjson|csv|markdown，默认按文件扩展名判断"
+// @Param dry_run query bool false "只校验不写入"
+// @Success 200 {string} string "导入完成"
+// @Failure 400 {string} string "导入文件解析失败"
+// @Router /posts/import [post]
+func (t TransferController) Import(ctx *gin.Context) {
+	db := t.DB.WithContext(ctx.Request.Context())
+	response.IgnoreFormatQuery(ctx)
+	maxSize := viper.GetInt64("post.import_max_size")
+	if maxSize <= 0 {
+		maxSize = defaultImportMaxSize
+	}
+	// 解析表单前限制请求体大小，超大的请求不会被完整读取
+	if ctx.Request.ContentLength > maxSize+importFormOverhead {
+		ctx.Error(errcode.New(errcode.ImportFileTooLarge, maxSize>>20))
+		return
+	}
+	ctx.Request.Body = http.MaxBytesReader(ctx.Writer, ctx.Request.Body, maxSize+importFormOverhead)
+
+	file, err := ctx.FormFile("file")
+	if err != nil {
+		if bodyTooLarge(err) {
+			ctx.Error(errcode.New(errcode.ImportFileTooLarge, maxSize>>20))
+			return
+		}
+		ctx.Error(errcode.New(errcode.ImportFileRequired))
+		return
+	}
+	if file.Size > maxSize {
+		ctx.Error(errcode.New(errcode.ImportFileTooLarge, maxSize>>20))
+		return
+	}
+
+	format := fileFormat(ctx)
+	if format == "" {
+		format = transfer.FormatOf(file.Filename)
+	}
+	if err := transfer.ValidFormat(format); err != nil {
+		ctx.Error(errcode.New(errcode.UnsupportedFormat, format))
+		return
+	}
+
+	f, err := file.Open()
+	if err != nil {
+		ctx.Error(errcode.Wrap(err, errcode.ImportReadFailed))
+		return
+	}
+	defer f.Close()
+	data, err := ioutil.ReadAll(f)
+	if err != nil {
+		ctx.Error(errcode.Wrap(err, errcode.ImportReadFailed))
+		return
+	}
+
+	items, err := transfer.Parse(format, data)
+	if errors.Is(err, transfer.ErrArchiveTooLarge) {
+		ctx.Error(errcode.New(errcode.ImportFileTooLarge, transfer.MaxArchiveSize>>20).WithData(gin.H{"error": err.Error()}))
+		return
+	}
+	if err != nil {
+		ctx.Error(errcode.Wrap(err, errcode.ImportParseFailed).WithData(gin.H{"error": err.Error()}))
+		return
+	}
+
+	user, _ := ctx.Get("user")
+	dryRun, _ := strconv.ParseBool(ctx.Query("dry_run"))
+	importer := transfer.Importer{DB: db, UserID: user.(model.User).ID, DryRun: dryRun, Lang: i18n.FromContext(ctx), Logger: logger.FromContext(ctx)}
+	results, created := importer.Import(items)
+
+	if len(created) > 0 {
+		metrics.PostsCreated.Add(float64(len(created)))
+		if err := t.Searcher.Index(created...); err != nil {
+			logger.FromContext(ctx).Error("index imported posts failed", "error", err)
+		}
+	}
+
+	succeeded := 0
+	for _, result := range results {
+		if result.Success {
+			succeeded++
+		}
+	}
+	response.Success(ctx, gin.H{
+		"dry_run":   dryRun,
+		"total":     len(results),
+		"succeeded": succeeded,
+		"failed":    len(results) - succeeded,
+		"results":   results,
+	}, "import_completed")
+}
+
+// bodyTooLarge 请求体超过 http.MaxBytesReader 的限制
+func bodyTooLarge(err error) bool {
+	return err != nil && strings.Contains(err.Error(), "http: request body too large")
+}
@@ -0,0 +1,141 @@
+package controller
+
+import (
+	"gin-swagger/dao"
+	"gin-swagger/errcode"
+	"gin-swagger/logger"
+	"gin-swagger/model"
+	"gin-swagger/response"
+	"gin-swagger/search"
+	"gin-swagger/trash"
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+	"strconv"
+)
+
+type ITrashController interface {
+	PageList(ctx *gin.Context)
+	Restore(ctx *gin.Context)
+	Purge(ctx *gin.Context)
+}
+
+type TrashController struct {
+	DB       *gorm.DB
+	Searcher search.Searcher
+}
+
+func NewTrashController() ITrashController {
+	return TrashController{DB: dao.GetDB(), Searcher: search.GetSearcher()}
+}
+
+// PageList 回收站列表模块
+// @Summary 回收站列表接口
+// @Schemes
+// @Description 分页列出当前用户回收站中的文章，按删除时间倒序
+// @Tags 文章回收站
+// @Accept application/json
+// @Produce application/json
+// @Param Authorization header string false "Bearer 用户令牌"
+// @Param pageNum query integer false "页码"
+// @Param pageSize query integer false "每页条数"
+// @Success 200 {string} string "成功"
+// @Router /posts/trash [get]
+func (t TrashController) PageList(ctx *gin.Context) {
+	db := t.DB.WithContext(ctx.Request.Context())
+	pageNum, _ := strconv.Atoi(ctx.DefaultQuery("pageNum", "1"))
+	pageSize, _ := strconv.Atoi(ctx.DefaultQuery("pageSize", "20"))
+
+	user, _ := ctx.Get("user")
+	query := db.Unscoped().Model(model.Post{}).
+		Where("user_id = ? AND deleted_at IS NOT NULL", user.(model.User).ID)
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		ctx.Error(errcode.Wrap(err, errcode.Internal))
+		return
+	}
+	var posts []model.Post
+	if err := query.Order("deleted_at desc").Offset((pageNum - 1) * pageSize).Limit(pageSize).Find(&posts).Error; err != nil {
+		ctx.Error(errcode.Wrap(err, errcode.Internal))
+		return
+	}
+
+	response.Success(ctx, gin.H{"data": posts, "total": total}, "success")
+}
+
+// Restore 恢复文章模块
+// @Summary 恢复文章接口
+// @Schemes
+// @Description 将回收站中的文章恢复
+// @Tags 文章回收站
+// @Accept application/json
+// @Produce application/json
+// @Param Authorization header string false "Bearer 用户令牌"
+// @Param id path string true "文章ID"
+// @Success 200 {string} string "恢复成功"
+// @Failure 404 {string} string "回收站中不存在该文章"
+// @Router /posts/{id}/restore [put]
+func (t TrashController) Restore(ctx *gin.Context) {
+	db := t.DB.WithContext(ctx.Request.Context())
+	post, ok := t.trashed(ctx)
+	if !ok {
+		return
+	}
+
+	if err := db.Unscoped().Model(&post).Update("deleted_at", nil).Error; err != nil {
+		ctx.Error(errcode.Wrap(err, errcode.PostRestoreFailed))
+		return
+	}
+
+	// 同步检索索引
+	if err := t.Searcher.Index(post); err != nil {
+		logger.FromContext(ctx).Error("index restored post failed", "post_id", post.ID, "error", err)
+	}
+	response.Success(ctx, gin.H{"post": post}, "post_restored")
+}
+
+// Purge 彻底删除文章模块
+// @Summary 彻底删除文章接口
+// @Schemes
+// @Description 彻底删除回收站中的文章及其评论、点赞和收藏，不可恢复
+// @Tags 文章回收站
+// @Accept application/json
+// @Produce application/json
+// @Param Authorization header string false "Bearer 用户令牌"
+// @Param id path string true "文章ID"
+// @Success 200 {string} string "彻底删除成功"
+// @Failure 404 {string} string "回收站中不存在该文章"
+// @Router /posts/{id}/permanent [delete]
+func (t TrashController) Purge(ctx *gin.Context) {
+	db := t.DB.WithContext(ctx.Request.Context())
+	post, ok := t.trashed(ctx)
+	if !ok {
+		return
+	}
+
+	if err := trash.Purge(db, post.ID.String()); err != nil {
+		ctx.Error(errcode.Wrap(err, errcode.PostPurgeFailed))
+		return
+	}
+	response.Success(ctx, nil, "post_purged")
+}
+
+// trashed 查找当前用户回收站中的文章
+func (t TrashController) trashed(ctx *gin.Context) (model.Post, bool) {
+	db := t.DB.WithContext(ctx.Request.Context())
+	postID := ctx.Params.ByName("id")
+
+	var post model.Post
+	err := db.Unscoped().Where("id = ? AND deleted_at IS NOT NULL", postID).First(&post).Error
+	if err != nil {
+		ctx.Error(errcode.New(errcode.TrashPostNotFound))
+		return post, false
+	}
+
+	user, _ := ctx.Get("user")
+	if user.(model.User).ID != post.UserID {
+		ctx.Error(errcode.New(errcode.NotPostAuthor))
+		return post, false
+	}
+	return post, true
+}
@@ -1,16 +1,16 @@
 package controller
 
 import (
-	"fmt"
 	"gin-swagger/dao"
 	"gin-swagger/dto"
+	"gin-swagger/errcode"
+	"gin-swagger/metrics"
 	"gin-swagger/model"
 	"gin-swagger/response"
 	"gin-swagger/util"
 	"github.com/gin-gonic/gin"
 	"golang.org/x/crypto/bcrypt"
 	"gorm.io/gorm"
-	"log"
 	"net/http"
 )
 
@@ -36,50 +36,42 @@ func Helloworld(g *gin.Context)  {
 // @Tags 用户注册
 // @Accept application/json
 // @Produce application/json
-// @Param object query model.User false "查询参数"
+// @Param object query dto.RegisterRequest false "注册参数"
 // @Success 200 {string} string "注册成功"
 // @Failure 400 {string} string "注册失败"
 // @Router /api/auth/register [post]
 func Register(ctx *gin.Context) {
-	DB := dao.GetDB()
-	//使用map获取请求参数
-	var requestUser model.User
-	ctx.Bind(&requestUser)
+	DB := dao.GetDB().WithContext(ctx.Request.Context())
+	// 获取并验证请求参数
+	var requestUser dto.RegisterRequest
+	if err := ctx.ShouldBind(&requestUser); err != nil {
+		ctx.Error(errcode.Invalid(err))
+		return
+	}
 	//获取参数
 	name := requestUser.Name
 	telephone := requestUser.Telephone
 	password := requestUser.Password
 
-	fmt.Println(telephone, "手机号码长度", len(telephone))
 	//name := ctx.PostForm("name")
 	//telephone := ctx.PostForm("telephone")
 	//password := ctx.PostForm("password")
-	// 数据验证
-	if len(telephone) != 11 {
-		response.Response(ctx, http.StatusUnprocessableEntity, 422, nil, "手机号必须为11位")
-		return
-	}
-	if len(password) < 6 {
-		response.Response(ctx, http.StatusUnprocessableEntity, 422, nil, "密码不能少于6位")
-		return
-	}
 
 	// 如果名称没有传，给一个10位的随机字符串
 	if len(name) ==0 {
 		name = util.RandomString(10)
 	}
 
-	log.Println(name, telephone, password)
 	// 判断手机号是否存在
 	if isTelephoneExist(DB, telephone) {
-		response.Response(ctx, http.StatusUnprocessableEntity, 422, nil, "用户已经存在")
+		ctx.Error(errcode.New(errcode.UserExists))
 		return
 	}
 
 	// 密码加密
 	hasepassword, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
 	if err != nil {
-		response.Response(ctx, http.StatusInternalServerError, 500, nil, "加密错误")
+		ctx.Error(errcode.Wrap(err, errcode.EncryptFailed))
 		return
 	}
 	// 创建用户
@@ -88,10 +80,14 @@ func Register(ctx *gin.Context) {
 		Telephone: telephone,
 		Password: string(hasepassword),
 	}
-	DB.Create(&newUser)
+	if err := DB.Create(&newUser).Error; err != nil {
+		ctx.Error(errcode.Wrap(err, errcode.Internal))
+		return
+	}
+	metrics.UserRegistrations.Inc()
 
 	// 返回结果
-	response.Success(ctx, nil, "注册成功")
+	response.Success(ctx, nil, "register_success")
 }
 
 // Login 用户登陆模块
@@ -107,56 +103,51 @@ func Register(ctx *gin.Context) {
 // @Failure 400 {string} string "登陆失败"
 // @Router /api/auth/login [post]
 func Login(ctx *gin.Context)  {
-	DB := dao.GetDB()
+	DB := dao.GetDB().WithContext(ctx.Request.Context())
 
-	// 使用map获取请求参数
-	var requestUser model.User
-	ctx.Bind(&requestUser)
+	// 获取并验证请求参数
+	var requestUser dto.LoginRequest
+	if err := ctx.ShouldBind(&requestUser); err != nil {
+		ctx.Error(errcode.Invalid(err))
+		return
+	}
 	//获取参数
 	telephone := requestUser.Telephone
 	password := requestUser.Password
 
-	// 数据验证
-	if len(telephone) != 11 {
-		response.Response(ctx, http.StatusUnprocessableEntity, 422, nil, "手机号必须为11位")
-		return
-	}
-	if len(password) < 6 {
-		response.Response(ctx, http.StatusUnprocessableEntity, 422, nil, "密码不能少于6位")
-		return
-	}
-
 	// 判断手机号是否存在
 	var user model.User
 	DB.Where("telephone = ?", telephone).First(&user)
 	if user.ID == 0 {
-		response.Response(ctx, http.StatusUnprocessableEntity, 422, nil, "用户不存在")
+		metrics.UserLogins.WithLabelValues("failure").Inc()
+		ctx.Error(errcode.New(errcode.UserNotFound))
 		return
 	}
 
 	// 判断密码是否正确
 	if err := bcrypt.CompareHashAndPassword([]byte(user.Password), []byte(password) ); err != nil {
-		response.Response(ctx, http.StatusBadRequest, 400, nil, "密码错误")
+		metrics.UserLogins.WithLabelValues("failure").Inc()
+		ctx.Error(errcode.New(errcode.PasswordIncorrect))
 		return
 	}
 
 	// 发放token
 	token, err := dao.ReleaseToken(user)
 	if err != nil {
-		response.Response(ctx, http.StatusInternalServerError, 500, nil, "系统异常")
-		log.Printf("token generate error ： %v", err)
+		ctx.Error(errcode.Wrap(err, errcode.Internal))
 		return
 	}
+	metrics.UserLogins.WithLabelValues("success").Inc()
 	
 	// 返回结果
-	response.Success(ctx, gin.H{"token": token}, "注册成功")
+	response.Success(ctx, gin.H{"token": token}, "login_success")
 
 }
 
 // Info 获取用户信息模块
 func Info(ctx  *gin.Context)  {
 	user, _ := ctx.Get("user")
-	response.Success(ctx, gin.H{ "user": dto.ToUserDto(user.(model.User)) }, "Token授权成功")
+	response.Success(ctx, gin.H{ "user": dto.ToUserDto(user.(model.User)) }, "token_authorized")
 }
 
 func isTelephoneExist(db *gorm.DB, telephone string) bool {
@@ -0,0 +1,147 @@
+package counter
+
+import (
+	"gin-swagger/logger"
+	"gin-swagger/model"
+	"github.com/spf13/viper"
+	"gorm.io/gorm"
+	"sync"
+	"time"
+)
+
+// ViewCounter 文章浏览计数器
+// 同一访客在去重窗口内重复浏览只计一次，累计的增量定期批量写入数据库
+type ViewCounter struct {
+	db       *gorm.DB
+	window   time.Duration
+	interval time.Duration
+
+	mu      sync.Mutex
+	seen    map[string]time.Time
+	pending map[string]int64
+
+	stop chan struct{}
+	done chan struct{}
+}
+
+var viewCounter *ViewCounter
+
+func NewViewCounter(db *gorm.DB, window time.Duration, interval time.Duration) *ViewCounter {
+	return &ViewCounter{
+		db:       db,
+		window:   window,
+		interval: interval,
+		seen:     make(map[string]time.Time),
+		pending:  make(map[string]int64),
+	}
+}
+
+func InitViewCounter(db *gorm.DB) *ViewCounter {
+	window := viper.GetDuration("post.view_window")
+	if window <= 0 {
+		window = 30 * time.Minute
+	}
+	interval := viper.GetDuration("post.view_flush_interval")
+	if interval <= 0 {
+		interval = 10 * time.Second
+	}
+
+	viewCounter = NewViewCounter(db, window, interval)
+	return viewCounter
+}
+
+func GetViewCounter() *ViewCounter {
+	return viewCounter
+}
+
+// Hit 记录一次浏览，viewer 为用户或IP标识，返回本次是否计数
+func (c *ViewCounter) Hit(postID string, viewer string) bool {
+	key := postID + "|" + viewer
+	now := time.Now()
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if expiry, ok := c.seen[key]; ok && now.Before(expiry) {
+		return false
+	}
+	c.seen[key] = now.Add(c.window)
+	c.pending[postID]++
+	return true
+}
+
+// Start 启动后台定时写库
+func (c *ViewCounter) Start() {
+	c.stop = make(chan struct{})
+	c.done = make(chan struct{})
+
+	go func() {
+		defer close(c.done)
+		ticker := time.NewTicker(c.interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ticker.C:
+				c.flushAndLog()
+				c.prune()
+			case <-c.stop:
+				c.flushAndLog()
+				return
+			}
+		}
+	}()
+}
+
+// Stop 停止后台任务并写入剩余增量
+func (c *ViewCounter) Stop() {
+	if c.stop == nil {
+		return
+	}
+	close(c.stop)
+	<-c.done
+	c.stop = nil
+}
+
+// Flush 将累计的浏览增量写入数据库，写入失败的增量会保留到下次
+func (c *ViewCounter) Flush() error {
+	c.mu.Lock()
+	pending := c.pending
+	c.pending = make(map[string]int64)
+	c.mu.Unlock()
+
+	var firstErr error
+	for postID, delta := range pending {
+		err := c.db.Model(&model.Post{}).Where("id = ?", postID).
+			UpdateColumn("view_count", gorm.Expr("view_count + ?", delta)).Error
+		if err != nil {
+			c.mu.Lock()
+			c.pending[postID] += delta
+			c.mu.Unlock()
+			if firstErr == nil {
+				firstErr = err
+			}
+		}
+	}
+	return firstErr
+}
+
+func (c *ViewCounter) flushAndLog() {
+	if err := c.Flush(); err != nil {
+		logger.GetLogger().Error("flush post views failed", "error", err)
+	}
+}
+
+// prune 清理已过去重窗口的访客记录
+func (c *ViewCounter) prune() {
+	now := time.Now()
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for key, expiry := range c.seen {
+		if !now.Before(expiry) {
+			delete(c.seen, key)
+		}
+	}
+}
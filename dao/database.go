@@ -2,7 +2,9 @@ package dao
 
 import (
 	"fmt"
+	"gin-swagger/metrics"
 	"gin-swagger/model"
+	"gin-swagger/tracing"
 	"github.com/spf13/viper"
 	"gorm.io/driver/mysql"
 	"gorm.io/gorm"
@@ -33,6 +35,12 @@ func InitDB() *gorm.DB {
 		panic("failed to  connect database, err: " + err.Error())
 	}
 	db.AutoMigrate(&model.User{})
+	if err := metrics.InstrumentDB(db, database); err != nil {
+		panic("failed to instrument database, err: " + err.Error())
+	}
+	if err := tracing.InstrumentDB(db); err != nil {
+		panic("failed to instrument database, err: " + err.Error())
+	}
 
 	DB = db
 	return db
@@ -40,4 +48,16 @@ func InitDB() *gorm.DB {
 
 func GetDB() *gorm.DB {
 	return DB
-}
\ No newline at end of file
+}
+
+// CloseDB 关闭数据库连接池
+func CloseDB() error {
+	if DB == nil {
+		return nil
+	}
+	sqlDB, err := DB.DB()
+	if err != nil {
+		return err
+	}
+	return sqlDB.Close()
+}
@@ -0,0 +1,14 @@
+package dto
+
+type CreateCommentRequest struct {
+	ParentID *uint `json:"parent_id" form:"parent_id"`
+	Content string `json:"content" form:"content" binding:"required,max=1000"`
+}
+
+type UpdateCommentRequest struct {
+	Content string `json:"content" form:"content" binding:"required,max=1000"`
+}
+
+type ModerateCommentRequest struct {
+	Status string `json:"status" form:"status" binding:"required,oneof=pending approved spam"`
+}
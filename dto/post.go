@@ -1,8 +1,32 @@
 package dto
 
+import "gin-swagger/errcode"
+
 type CreatePostRequest struct {
 	CategoryID uint `json:"category_id" form:"category_id" binding:"required"`
 	Title string `json:"title" form:"title" binding:"required,max=10"`
 	HeadImg string `json:"head_img" form:"head_img"`
 	Content string `json:"content" form:"content" binding:"required"`
+	Status string `json:"status" form:"status" binding:"omitempty,oneof=draft published"`
+	CommentsDisabled bool `json:"comments_disabled" form:"comments_disabled"`
+}
+
+type CommentSettingRequest struct {
+	Disabled *bool `json:"disabled" form:"disabled" binding:"required"`
+}
+
+type BulkPostRequest struct {
+	IDs []string `json:"ids" form:"ids" binding:"required,min=1,dive,uuid"`
+	Operation string `json:"operation" form:"operation" binding:"required,oneof=delete move_category change_status add_tags remove_tags"`
+	CategoryID uint `json:"category_id" form:"category_id"`
+	Status string `json:"status" form:"status" binding:"omitempty,oneof=draft published"`
+	Tags []string `json:"tags" form:"tags" binding:"omitempty,dive,required,max=30"`
+	Atomic bool `json:"atomic" form:"atomic"`
+}
+
+type BulkItemResult struct {
+	ID string `json:"id"`
+	Success bool `json:"success"`
+	Error errcode.Code `json:"error,omitempty"`
+	Msg string `json:"msg"`
 }
@@ -2,6 +2,18 @@ package dto
 
 import "gin-swagger/model"
 
+type RegisterRequest struct {
+	Name string `json:"name" form:"name" binding:"omitempty,max=20"`
+	Telephone string `json:"telephone" form:"telephone" binding:"required,telephone"`
+	Password string `json:"password" form:"password" binding:"required,min=6"`
+}
+
+// LoginRequest 登陆不校验手机号格式，格式规则收紧前注册的账号仍可登陆
+type LoginRequest struct {
+	Telephone string `json:"telephone" form:"telephone" binding:"required"`
+	Password string `json:"password" form:"password" binding:"required,min=6"`
+}
+
 type UserDto struct {
 	Name string `json:"name"`
 	Telephone string `json:"telephone"`
@@ -12,4 +24,16 @@ func ToUserDto(user model.User) UserDto {
 		Name: user.Name,
 		Telephone: user.Telephone,
 	}
-}
\ No newline at end of file
+}
+// AuthorDto 文章作者的公开信息
+type AuthorDto struct {
+	ID uint `json:"id"`
+	Name string `json:"name"`
+}
+
+func ToAuthorDto(user model.User) AuthorDto {
+	return AuthorDto{
+		ID: user.ID,
+		Name: user.Name,
+	}
+}
@@ -0,0 +1,146 @@
+package errcode
+
+import "net/http"
+
+// 通用错误
+const (
+	InvalidParams     Code = "invalid_params"
+	Unauthorized      Code = "unauthorized"
+	AdminRequired     Code = "admin_required"
+	Internal          Code = "internal_error"
+	OperationFailed   Code = "operation_failed"
+	UnsupportedFormat Code = "unsupported_format"
+	NotAcceptable     Code = "not_acceptable"
+	InvalidField      Code = "invalid_field"
+	InvalidExpand     Code = "invalid_expand"
+	TooManyRequests   Code = "too_many_requests"
+
+	InvalidIdempotencyKey Code = "invalid_idempotency_key"
+	IdempotencyKeyReused  Code = "idempotency_key_reused"
+	IdempotencyInProgress Code = "idempotency_in_progress"
+)
+
+// 用户
+const (
+	PasswordIncorrect Code = "password_incorrect"
+	UserExists        Code = "user_exists"
+	UserNotFound      Code = "user_not_found"
+	EncryptFailed     Code = "encrypt_failed"
+)
+
+// 分类
+const (
+	CategoryNotFound     Code = "category_not_found"
+	CategoryDeleteFailed Code = "category_delete_failed"
+)
+
+// 文章
+const (
+	PostNotFound             Code = "post_not_found"
+	NotPostAuthor            Code = "not_post_author"
+	PostRenderFailed         Code = "post_render_failed"
+	PostUpdateFailed         Code = "post_update_failed"
+	PostDeleteFailed         Code = "post_delete_failed"
+	PostSettingFailed        Code = "post_setting_failed"
+	KeywordRequired          Code = "keyword_required"
+	SearchFailed             Code = "search_failed"
+	TrashPostNotFound        Code = "trash_post_not_found"
+	PostRestoreFailed        Code = "post_restore_failed"
+	PostPurgeFailed          Code = "post_purge_failed"
+	BulkTooManyItems         Code = "bulk_too_many_items"
+	BulkStatusRequired       Code = "bulk_status_required"
+	BulkTagsRequired         Code = "bulk_tags_required"
+	BulkRolledBack           Code = "bulk_rolled_back"
+	BulkUnsupportedOperation Code = "bulk_unsupported_operation"
+	ImportFileRequired       Code = "import_file_required"
+	ImportFileTooLarge       Code = "import_file_too_large"
+	ImportReadFailed         Code = "import_read_failed"
+	ImportParseFailed        Code = "import_parse_failed"
+	ImportRecordParseFailed  Code = "import_record_parse_failed"
+	ImportCategoryRequired   Code = "import_category_required"
+	ImportCategoryNotFound   Code = "import_category_not_found"
+	ImportCreatedAtInvalid   Code = "import_created_at_invalid"
+)
+
+// 评论
+const (
+	CommentNotFound       Code = "comment_not_found"
+	NotCommentAuthor      Code = "not_comment_author"
+	ParentCommentNotFound Code = "parent_comment_not_found"
+	CommentsDisabled      Code = "comments_disabled"
+	CommentCreateFailed   Code = "comment_create_failed"
+	CommentUpdateFailed   Code = "comment_update_failed"
+	CommentDeleteFailed   Code = "comment_delete_failed"
+	CommentModerateFailed Code = "comment_moderate_failed"
+)
+
+// 订阅和站点地图
+const (
+	AuthorNotFound       Code = "author_not_found"
+	FeedNotFound         Code = "feed_not_found"
+	SitemapShardNotFound Code = "sitemap_shard_not_found"
+)
+
+// statuses 错误码对应的 HTTP 状态码，提示文案见 i18n 消息目录
+var statuses = map[Code]int{
+	InvalidParams:     http.StatusBadRequest,
+	Unauthorized:      http.StatusUnauthorized,
+	AdminRequired:     http.StatusForbidden,
+	Internal:          http.StatusInternalServerError,
+	OperationFailed:   http.StatusInternalServerError,
+	UnsupportedFormat: http.StatusBadRequest,
+	NotAcceptable:     http.StatusNotAcceptable,
+	InvalidField:      http.StatusBadRequest,
+	InvalidExpand:     http.StatusBadRequest,
+	TooManyRequests:   http.StatusTooManyRequests,
+
+	InvalidIdempotencyKey: http.StatusBadRequest,
+	IdempotencyKeyReused:  http.StatusConflict,
+	IdempotencyInProgress: http.StatusConflict,
+
+	PasswordIncorrect: http.StatusBadRequest,
+	UserExists:        http.StatusUnprocessableEntity,
+	UserNotFound:      http.StatusUnprocessableEntity,
+	EncryptFailed:     http.StatusInternalServerError,
+
+	CategoryNotFound:     http.StatusNotFound,
+	CategoryDeleteFailed: http.StatusInternalServerError,
+
+	PostNotFound:             http.StatusNotFound,
+	NotPostAuthor:            http.StatusForbidden,
+	PostRenderFailed:         http.StatusInternalServerError,
+	PostUpdateFailed:         http.StatusInternalServerError,
+	PostDeleteFailed:         http.StatusInternalServerError,
+	PostSettingFailed:        http.StatusInternalServerError,
+	KeywordRequired:          http.StatusBadRequest,
+	SearchFailed:             http.StatusInternalServerError,
+	TrashPostNotFound:        http.StatusNotFound,
+	PostRestoreFailed:        http.StatusInternalServerError,
+	PostPurgeFailed:          http.StatusInternalServerError,
+	BulkTooManyItems:         http.StatusBadRequest,
+	BulkStatusRequired:       http.StatusBadRequest,
+	BulkTagsRequired:         http.StatusBadRequest,
+	BulkRolledBack:           http.StatusUnprocessableEntity,
+	BulkUnsupportedOperation: http.StatusBadRequest,
+	ImportFileRequired:       http.StatusBadRequest,
+	ImportFileTooLarge:       http.StatusRequestEntityTooLarge,
+	ImportReadFailed:         http.StatusBadRequest,
+	ImportParseFailed:        http.StatusBadRequest,
+	ImportRecordParseFailed:  http.StatusBadRequest,
+	ImportCategoryRequired:   http.StatusBadRequest,
+	ImportCategoryNotFound:   http.StatusBadRequest,
+	ImportCreatedAtInvalid:   http.StatusBadRequest,
+
+	CommentNotFound:       http.StatusNotFound,
+	NotCommentAuthor:      http.StatusForbidden,
+	ParentCommentNotFound: http.StatusBadRequest,
+	CommentsDisabled:      http.StatusForbidden,
+	CommentCreateFailed:   http.StatusInternalServerError,
+	CommentUpdateFailed:   http.StatusInternalServerError,
+	CommentDeleteFailed:   http.StatusInternalServerError,
+	CommentModerateFailed: http.StatusInternalServerError,
+
+	AuthorNotFound:       http.StatusNotFound,
+	FeedNotFound:         http.StatusNotFound,
+	SitemapShardNotFound: http.StatusNotFound,
+}
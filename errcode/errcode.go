@@ -0,0 +1,107 @@
+package errcode
+
+import (
+	"errors"
+	"gin-swagger/i18n"
+	"gin-swagger/validation"
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+	"net/http"
+	"strings"
+)
+
+// Code 错误码，客户端据此区分错误类型，同时作为 i18n 消息目录中提示文案的ID
+type Code string
+
+// FieldError 单个字段的校验错误，Field 为 JSON 字段路径，Rule 和 Param 为未通过的校验规则及其参数
+type FieldError struct {
+	Field   string `json:"field"`
+	Rule    string `json:"rule"`
+	Param   string `json:"param,omitempty"`
+	Message string `json:"message"`
+}
+
+// Error 业务错误，控制器通过 ctx.Error 返回，由 ErrorMiddleware 统一渲染
+type Error struct {
+	Code Code
+	Args []interface{}
+	Data gin.H
+	// invalid 参数绑定的校验错误，渲染时按请求语言翻译
+	invalid validator.ValidationErrors
+	cause   error
+}
+
+// New 创建错误，args 用于填充提示模板
+func New(code Code, args ...interface{}) *Error {
+	return &Error{Code: code, Args: args}
+}
+
+// Wrap 创建错误并记录原始错误，原始错误只用于日志，不返回给客户端
+func Wrap(err error, code Code, args ...interface{}) *Error {
+	return &Error{Code: code, Args: args, cause: err}
+}
+
+// Invalid 由参数绑定错误创建数据验证错误，校验失败的字段通过 FieldErrors 返回
+func Invalid(err error) *Error {
+	e := Wrap(err, InvalidParams)
+	errors.As(err, &e.invalid)
+	return e
+}
+
+// From 将任意错误转换为业务错误，未知错误视为系统异常
+func From(err error) *Error {
+	var e *Error
+	if errors.As(err, &e) {
+		return e
+	}
+	return Wrap(err, Internal)
+}
+
+// WithData 附加返回给客户端的数据
+func (e *Error) WithData(data gin.H) *Error {
+	e.Data = data
+	return e
+}
+
+// Status 错误对应的 HTTP 状态码
+func (e *Error) Status() int {
+	if status, ok := statuses[e.Code]; ok {
+		return status
+	}
+	return http.StatusInternalServerError
+}
+
+// Message 指定语言的提示信息
+func (e *Error) Message(lang string) string {
+	return i18n.T(lang, string(e.Code), e.Args...)
+}
+
+// FieldErrors 指定语言的字段校验错误
+func (e *Error) FieldErrors(lang string) []FieldError {
+	fields := make([]FieldError, 0, len(e.invalid))
+	for _, fe := range e.invalid {
+		// 去掉命名空间开头的结构体名，保留嵌套字段路径
+		field := fe.Namespace()
+		if i := strings.Index(field, "."); i >= 0 {
+			field = field[i+1:]
+		}
+		fields = append(fields, FieldError{
+			Field:   field,
+			Rule:    fe.Tag(),
+			Param:   fe.Param(),
+			Message: validation.Translate(lang, fe),
+		})
+	}
+	return fields
+}
+
+func (e *Error) Error() string {
+	if e.cause != nil {
+		return string(e.Code) + ": " + e.cause.Error()
+	}
+	return string(e.Code) + ": " + e.Message(i18n.DefaultLanguage())
+}
+
+func (e *Error) Unwrap() error {
+	return e.cause
+}
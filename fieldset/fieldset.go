@@ -0,0 +1,119 @@
+package fieldset
+
+import (
+	"bytes"
+	"encoding/json"
+	"gin-swagger/errcode"
+	"github.com/gin-gonic/gin"
+	"strings"
+)
+
+// Resource 资源允许通过 fields 选择的字段和通过 expand 展开的关联，字段名为 JSON 字段名
+type Resource struct {
+	Fields  []string
+	Expands []string
+}
+
+// Selection 一次请求选择的字段和需要展开的关联
+type Selection struct {
+	// fields 为 nil 表示返回全部字段
+	fields    map[string]bool
+	expands   map[string]bool
+	relations map[string]bool
+}
+
+// Parse 解析逗号分隔的 fields 和 expand 查询参数，不在白名单中的字段或关联返回数据验证错误
+func (r Resource) Parse(ctx *gin.Context) (Selection, error) {
+	s := Selection{expands: map[string]bool{}, relations: map[string]bool{}}
+	for _, name := range r.Expands {
+		s.relations[name] = true
+	}
+
+	if value := ctx.Query("fields"); value != "" {
+		s.fields = map[string]bool{}
+		for _, name := range split(value) {
+			if !contains(r.Fields, name) {
+				return s, errcode.New(errcode.InvalidField, name)
+			}
+			s.fields[name] = true
+		}
+	}
+	for _, name := range split(ctx.Query("expand")) {
+		if !s.relations[name] {
+			return s, errcode.New(errcode.InvalidExpand, name)
+		}
+		s.expands[name] = true
+	}
+	return s, nil
+}
+
+// Expanded 是否需要展开关联
+func (s Selection) Expanded(name string) bool {
+	return s.expands[name]
+}
+
+// Columns 查询所需的列，字段名与列名一致时可直接用于 Select；未选择字段时返回 nil 表示查询全部列
+func (s Selection) Columns(required ...string) []string {
+	if s.fields == nil {
+		return nil
+	}
+	columns := append([]string{}, required...)
+	for name := range s.fields {
+		if !contains(columns, name) {
+			columns = append(columns, name)
+		}
+	}
+	return columns
+}
+
+// Apply 将对象按 JSON 字段裁剪为所选字段，未展开的关联一律去掉
+func (s Selection) Apply(obj interface{}) (gin.H, error) {
+	data, err := json.Marshal(obj)
+	if err != nil {
+		return nil, err
+	}
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	var all map[string]interface{}
+	if err := decoder.Decode(&all); err != nil {
+		return nil, err
+	}
+
+	result := gin.H{}
+	for name, value := range all {
+		switch {
+		case s.relations[name]:
+			if s.expands[name] {
+				result[name] = value
+			}
+		case s.fields == nil || s.fields[name]:
+			result[name] = value
+		}
+	}
+	// 展开后为空的关联因 omitempty 不在 JSON 中，补为 null 以便客户端区分
+	for name := range s.expands {
+		if _, ok := result[name]; !ok {
+			result[name] = nil
+		}
+	}
+	return result, nil
+}
+
+func split(value string) []string {
+	var names []string
+	for _, name := range strings.Split(value, ",") {
+		if name = strings.TrimSpace(name); name != "" {
+			names = append(names, name)
+		}
+	}
+	return names
+}
+
+func contains(names []string, name string) bool {
+	for _, n := range names {
+		if n == name {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,148 @@
+package health
+
+import (
+	"context"
+	"fmt"
+	"gorm.io/gorm"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// 检查状态
+const (
+	StatusOK          = "ok"
+	StatusUnavailable = "unavailable"
+	StatusTimeout     = "timeout"
+	StatusShutdown    = "shutting_down"
+)
+
+// Checker 依赖检查，返回错误表示依赖不可用，应在 ctx 超时后尽快返回
+type Checker interface {
+	Check(ctx context.Context) error
+}
+
+// CheckerFunc 以函数实现 Checker
+type CheckerFunc func(ctx context.Context) error
+
+func (f CheckerFunc) Check(ctx context.Context) error {
+	return f(ctx)
+}
+
+// CheckResult 单项检查结果
+type CheckResult struct {
+	Status    string  `json:"status"`
+	LatencyMS float64 `json:"latency_ms"`
+	Error     string  `json:"error,omitempty"`
+}
+
+// Report 就绪检查报告
+type Report struct {
+	Status string                 `json:"status"`
+	Checks map[string]CheckResult `json:"checks"`
+}
+
+var (
+	mu       sync.RWMutex
+	checkers = map[string]Checker{}
+
+	shuttingDown int32
+)
+
+// Register 注册就绪检查，同名检查会被覆盖
+func Register(name string, checker Checker) {
+	mu.Lock()
+	defer mu.Unlock()
+	checkers[name] = checker
+}
+
+// SetShuttingDown 标记服务正在关闭，之后就绪检查一律返回不可用，让负载均衡摘除本实例
+func SetShuttingDown() {
+	atomic.StoreInt32(&shuttingDown, 1)
+}
+
+// ShuttingDown 服务是否正在关闭
+func ShuttingDown() bool {
+	return atomic.LoadInt32(&shuttingDown) == 1
+}
+
+// Check 并发执行全部检查，每项最长等待 timeout，全部通过时报告状态为 ok
+func Check(ctx context.Context, timeout time.Duration) Report {
+	mu.RLock()
+	names := make([]string, 0, len(checkers))
+	for name := range checkers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	list := make([]Checker, len(names))
+	for i, name := range names {
+		list[i] = checkers[name]
+	}
+	mu.RUnlock()
+
+	results := make([]CheckResult, len(names))
+	var wg sync.WaitGroup
+	for i := range list {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			results[i] = run(ctx, list[i], timeout)
+		}(i)
+	}
+	wg.Wait()
+
+	report := Report{Status: StatusOK, Checks: make(map[string]CheckResult, len(names))}
+	for i, name := range names {
+		report.Checks[name] = results[i]
+		if results[i].Status != StatusOK {
+			report.Status = StatusUnavailable
+		}
+	}
+	if ShuttingDown() {
+		report.Status = StatusShutdown
+	}
+	return report
+}
+
+// run 执行单项检查，检查不响应 ctx 时也在超时后返回
+func run(ctx context.Context, checker Checker, timeout time.Duration) CheckResult {
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	start := time.Now()
+	done := make(chan error, 1)
+	go func() {
+		defer func() {
+			if r := recover(); r != nil {
+				done <- fmt.Errorf("checker panic: %v", r)
+			}
+		}()
+		done <- checker.Check(ctx)
+	}()
+
+	result := CheckResult{Status: StatusOK}
+	select {
+	case err := <-done:
+		if err != nil {
+			result.Status = StatusUnavailable
+			result.Error = err.Error()
+		}
+	case <-ctx.Done():
+		result.Status = StatusTimeout
+		result.Error = ctx.Err().Error()
+	}
+	result.LatencyMS = float64(time.Since(start).Microseconds()) / 1000
+	return result
+}
+
+// DBChecker 检查数据库连接是否可用
+func DBChecker(db *gorm.DB) Checker {
+	return CheckerFunc(func(ctx context.Context) error {
+		sqlDB, err := db.DB()
+		if err != nil {
+			return err
+		}
+		return sqlDB.PingContext(ctx)
+	})
+}
@@ -0,0 +1,123 @@
+package i18n
+
+import (
+	"embed"
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"github.com/spf13/viper"
+	"gopkg.in/yaml.v2"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// ContextKey 协商出的语言在 gin 上下文中的键
+const ContextKey = "lang"
+
+//go:embed locales/*.yml
+var localeFiles embed.FS
+
+// catalogs 各语言的消息目录，键为消息ID
+var catalogs = map[string]map[string]string{}
+
+var defaultLanguage = "zh"
+
+// InitI18n 加载消息目录并设置默认语言
+func InitI18n() {
+	entries, err := localeFiles.ReadDir("locales")
+	if err != nil {
+		panic(err)
+	}
+	for _, entry := range entries {
+		data, err := localeFiles.ReadFile("locales/" + entry.Name())
+		if err != nil {
+			panic(err)
+		}
+		messages := map[string]string{}
+		if err := yaml.Unmarshal(data, &messages); err != nil {
+			panic(fmt.Errorf("load locale %s: %w", entry.Name(), err))
+		}
+		catalogs[strings.TrimSuffix(entry.Name(), path.Ext(entry.Name()))] = messages
+	}
+
+	if lang := viper.GetString("i18n.default_language"); lang != "" {
+		if _, ok := catalogs[lang]; !ok {
+			panic(fmt.Errorf("default language %q has no message catalog", lang))
+		}
+		defaultLanguage = lang
+	}
+}
+
+// DefaultLanguage 未能协商出语言时使用的默认语言
+func DefaultLanguage() string {
+	return defaultLanguage
+}
+
+// T 按消息ID取对应语言的文案，缺失时依次回退到默认语言和消息ID本身，args 用于填充模板
+func T(lang string, id string, args ...interface{}) string {
+	message, ok := catalogs[lang][id]
+	if !ok {
+		message, ok = catalogs[defaultLanguage][id]
+	}
+	if !ok {
+		message = id
+	}
+	if len(args) == 0 {
+		return message
+	}
+	return fmt.Sprintf(message, args...)
+}
+
+// FromContext 取请求协商出的语言，未经 LocaleMiddleware 时返回默认语言
+func FromContext(ctx *gin.Context) string {
+	if lang := ctx.GetString(ContextKey); lang != "" {
+		return lang
+	}
+	return defaultLanguage
+}
+
+// Negotiate 按 Accept-Language 的权重选择有消息目录的语言，先匹配完整标签再匹配主语言
+func Negotiate(acceptLanguage string) string {
+	type candidate struct {
+		tag     string
+		quality float64
+	}
+	var candidates []candidate
+	for _, part := range strings.Split(acceptLanguage, ",") {
+		fields := strings.Split(strings.TrimSpace(part), ";")
+		tag := strings.ToLower(strings.TrimSpace(fields[0]))
+		if tag == "" {
+			continue
+		}
+		quality := 1.0
+		for _, param := range fields[1:] {
+			param = strings.TrimSpace(param)
+			if strings.HasPrefix(param, "q=") {
+				if q, err := strconv.ParseFloat(param[2:], 64); err == nil {
+					quality = q
+				}
+			}
+		}
+		if quality > 0 {
+			candidates = append(candidates, candidate{tag, quality})
+		}
+	}
+	sort.SliceStable(candidates, func(i, j int) bool {
+		return candidates[i].quality > candidates[j].quality
+	})
+
+	for _, c := range candidates {
+		if c.tag == "*" {
+			return defaultLanguage
+		}
+		if _, ok := catalogs[c.tag]; ok {
+			return c.tag
+		}
+		primary := strings.SplitN(strings.ReplaceAll(c.tag, "_", "-"), "-", 2)[0]
+		if _, ok := catalogs[primary]; ok {
+			return primary
+		}
+	}
+	return defaultLanguage
+}
@@ -0,0 +1,55 @@
+package idempotency
+
+import (
+	"context"
+	"github.com/go-redis/redis/v8"
+	"github.com/spf13/viper"
+	"net/http"
+	"time"
+)
+
+// Record 一个幂等键对应的请求摘要和记录的响应，Completed 为 false 表示请求仍在处理
+type Record struct {
+	Fingerprint string      `json:"fingerprint"`
+	Completed   bool        `json:"completed"`
+	Status      int         `json:"status,omitempty"`
+	Header      http.Header `json:"header,omitempty"`
+	Body        []byte      `json:"body,omitempty"`
+}
+
+// Store 幂等键的存储
+type Store interface {
+	// Begin 键不存在时原子地写入处理中的记录并返回 acquired 为 true，记录在 lockTimeout 后过期；
+	// 键已存在时返回已有记录
+	Begin(ctx context.Context, key string, fingerprint string, lockTimeout time.Duration) (record *Record, acquired bool, err error)
+	// Complete 保存请求完成后的记录，在 ttl 后过期
+	Complete(ctx context.Context, key string, record *Record, ttl time.Duration) error
+	// Release 删除处理中的记录，让客户端可以重试，已完成的记录不删除
+	Release(ctx context.Context, key string, fingerprint string) error
+	// Ping 检查存储是否可用
+	Ping(ctx context.Context) error
+	Close() error
+}
+
+var store Store
+
+// InitStore 按配置 idempotency 创建存储，idempotency.store 为 memory 或 redis
+func InitStore() Store {
+	switch viper.GetString("idempotency.store") {
+	case "", "memory":
+		store = NewMemoryStore()
+	case "redis":
+		store = NewRedisStore(redis.NewClient(&redis.Options{
+			Addr:     viper.GetString("idempotency.redis.addr"),
+			Password: viper.GetString("idempotency.redis.password"),
+			DB:       viper.GetInt("idempotency.redis.db"),
+		}), viper.GetString("idempotency.redis.prefix"))
+	default:
+		panic("unsupported idempotency store: " + viper.GetString("idempotency.store"))
+	}
+	return store
+}
+
+func GetStore() Store {
+	return store
+}
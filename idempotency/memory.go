@@ -0,0 +1,77 @@
+package idempotency
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// sweepInterval 内存存储清理过期记录的间隔
+const sweepInterval = time.Minute
+
+type entry struct {
+	record  *Record
+	expires time.Time
+}
+
+// MemoryStore 单实例使用的内存存储，过期记录在访问时定期清理
+type MemoryStore struct {
+	mu        sync.Mutex
+	entries   map[string]*entry
+	lastSweep time.Time
+}
+
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{entries: make(map[string]*entry), lastSweep: time.Now()}
+}
+
+func (s *MemoryStore) Begin(ctx context.Context, key string, fingerprint string, lockTimeout time.Duration) (*Record, bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	now := time.Now()
+	s.sweep(now)
+
+	if e, ok := s.entries[key]; ok && now.Before(e.expires) {
+		return e.record, false, nil
+	}
+	record := &Record{Fingerprint: fingerprint}
+	s.entries[key] = &entry{record: record, expires: now.Add(lockTimeout)}
+	return record, true, nil
+}
+
+func (s *MemoryStore) Complete(ctx context.Context, key string, record *Record, ttl time.Duration) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.entries[key] = &entry{record: record, expires: time.Now().Add(ttl)}
+	return nil
+}
+
+func (s *MemoryStore) Release(ctx context.Context, key string, fingerprint string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if e, ok := s.entries[key]; ok && !e.record.Completed && e.record.Fingerprint == fingerprint {
+		delete(s.entries, key)
+	}
+	return nil
+}
+
+func (s *MemoryStore) Ping(ctx context.Context) error {
+	return nil
+}
+
+func (s *MemoryStore) Close() error {
+	return nil
+}
+
+// sweep 定期删除过期记录，调用方需持有锁
+func (s *MemoryStore) sweep(now time.Time) {
+	if now.Sub(s.lastSweep) < sweepInterval {
+		return
+	}
+	s.lastSweep = now
+	for key, e := range s.entries {
+		if !now.Before(e.expires) {
+			delete(s.entries, key)
+		}
+	}
+}
@@ -0,0 +1,88 @@
+package idempotency
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"github.com/go-redis/redis/v8"
+	"time"
+)
+
+// releaseScript 只在键仍是同一请求的处理中记录时删除，避免删掉锁过期后其他请求写入的记录
+var releaseScript = redis.NewScript(`
+if redis.call('GET', KEYS[1]) == ARGV[1] then
+	return redis.call('DEL', KEYS[1])
+end
+return 0
+`)
+
+// RedisStore 多实例共享的 Redis 存储，记录以 JSON 保存
+type RedisStore struct {
+	client redis.UniversalClient
+	prefix string
+}
+
+// NewRedisStore client 可以是任意兼容 Redis 协议的服务，prefix 为键前缀，默认 idempotency:
+func NewRedisStore(client redis.UniversalClient, prefix string) *RedisStore {
+	if prefix == "" {
+		prefix = "idempotency:"
+	}
+	return &RedisStore{client: client, prefix: prefix}
+}
+
+func (s *RedisStore) Begin(ctx context.Context, key string, fingerprint string, lockTimeout time.Duration) (*Record, bool, error) {
+	pending := &Record{Fingerprint: fingerprint}
+	value, err := json.Marshal(pending)
+	if err != nil {
+		return nil, false, err
+	}
+
+	// 已有记录恰好在 SETNX 和 GET 之间过期时重试
+	for attempt := 0; attempt < 3; attempt++ {
+		acquired, err := s.client.SetNX(ctx, s.prefix+key, value, lockTimeout).Result()
+		if err != nil {
+			return nil, false, err
+		}
+		if acquired {
+			return pending, true, nil
+		}
+
+		stored, err := s.client.Get(ctx, s.prefix+key).Bytes()
+		if errors.Is(err, redis.Nil) {
+			continue
+		}
+		if err != nil {
+			return nil, false, err
+		}
+		var record Record
+		if err := json.Unmarshal(stored, &record); err != nil {
+			return nil, false, err
+		}
+		return &record, false, nil
+	}
+	return nil, false, errors.New("idempotency key kept expiring while reading")
+}
+
+func (s *RedisStore) Complete(ctx context.Context, key string, record *Record, ttl time.Duration) error {
+	value, err := json.Marshal(record)
+	if err != nil {
+		return err
+	}
+	return s.client.Set(ctx, s.prefix+key, value, ttl).Err()
+}
+
+func (s *RedisStore) Release(ctx context.Context, key string, fingerprint string) error {
+	value, err := json.Marshal(&Record{Fingerprint: fingerprint})
+	if err != nil {
+		return err
+	}
+	return releaseScript.Run(ctx, s.client, []string{s.prefix + key}, value).Err()
+}
+
+func (s *RedisStore) Ping(ctx context.Context) error {
+	return s.client.Ping(ctx).Err()
+}
+
+func (s *RedisStore) Close() error {
+	return s.client.Close()
+}
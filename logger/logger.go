@@ -0,0 +1,263 @@
+package logger
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"github.com/spf13/viper"
+	"io"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ContextKey 请求日志在 gin 上下文中的键
+const ContextKey = "logger"
+
+// RequestIDKey 请求ID在 gin 上下文中的键
+const RequestIDKey = "request_id"
+
+// Redacted 敏感字段脱敏后的值
+const Redacted = "[REDACTED]"
+
+// defaultRedactKeys 未配置 log.redact_keys 时脱敏的字段，不区分大小写
+var defaultRedactKeys = []string{"password", "authorization", "cookie", "set-cookie", "token", "x-api-key"}
+
+// Level 日志级别
+type Level int8
+
+const (
+	LevelDebug Level = iota
+	LevelInfo
+	LevelWarn
+	LevelError
+)
+
+func (l Level) String() string {
+	switch l {
+	case LevelDebug:
+		return "debug"
+	case LevelWarn:
+		return "warn"
+	case LevelError:
+		return "error"
+	}
+	return "info"
+}
+
+// ParseLevel 解析 debug、info、warn、error
+func ParseLevel(s string) (Level, error) {
+	switch strings.ToLower(s) {
+	case "debug":
+		return LevelDebug, nil
+	case "", "info":
+		return LevelInfo, nil
+	case "warn", "warning":
+		return LevelWarn, nil
+	case "error":
+		return LevelError, nil
+	}
+	return LevelInfo, fmt.Errorf("unknown log level: %s", s)
+}
+
+type output struct {
+	mu sync.Mutex
+	w  io.Writer
+}
+
+// Logger 每行输出一个 JSON 对象的结构化日志，字段以键值对传入，敏感字段按名称脱敏
+type Logger struct {
+	out    *output
+	level  Level
+	redact map[string]bool
+	fields []interface{}
+}
+
+var logger = New(os.Stdout, LevelInfo, defaultRedactKeys...)
+
+func New(w io.Writer, level Level, redactKeys ...string) *Logger {
+	redact := make(map[string]bool, len(redactKeys))
+	for _, key := range redactKeys {
+		redact[strings.ToLower(key)] = true
+	}
+	return &Logger{out: &output{w: w}, level: level, redact: redact}
+}
+
+// InitLogger 按配置 log 创建日志，log.output 为 stdout、stderr 或文件路径
+func InitLogger() *Logger {
+	level, err := ParseLevel(viper.GetString("log.level"))
+	if err != nil {
+		panic(err)
+	}
+
+	var w io.Writer
+	switch path := viper.GetString("log.output"); path {
+	case "", "stdout":
+		w = os.Stdout
+	case "stderr":
+		w = os.Stderr
+	default:
+		file, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+		if err != nil {
+			panic("failed to open log file, err: " + err.Error())
+		}
+		w = file
+	}
+
+	redactKeys := defaultRedactKeys
+	if viper.IsSet("log.redact_keys") {
+		redactKeys = viper.GetStringSlice("log.redact_keys")
+	}
+
+	logger = New(w, level, redactKeys...)
+	return logger
+}
+
+func GetLogger() *Logger {
+	return logger
+}
+
+// FromContext 获取请求日志，带有请求ID等请求字段；不在请求中时返回全局日志
+func FromContext(ctx *gin.Context) *Logger {
+	if ctx != nil {
+		if l, ok := ctx.Get(ContextKey); ok {
+			return l.(*Logger)
+		}
+	}
+	return logger
+}
+
+// RequestID 获取当前请求的请求ID
+func RequestID(ctx *gin.Context) string {
+	return ctx.GetString(RequestIDKey)
+}
+
+// With 返回附加了字段的子日志
+func (l *Logger) With(keysAndValues ...interface{}) *Logger {
+	child := *l
+	child.fields = append(append([]interface{}{}, l.fields...), keysAndValues...)
+	return &child
+}
+
+// Enabled 是否输出该级别的日志
+func (l *Logger) Enabled(level Level) bool {
+	return level >= l.level
+}
+
+func (l *Logger) Debug(msg string, keysAndValues ...interface{}) {
+	l.log(LevelDebug, msg, keysAndValues)
+}
+
+func (l *Logger) Info(msg string, keysAndValues ...interface{}) {
+	l.log(LevelInfo, msg, keysAndValues)
+}
+
+func (l *Logger) Warn(msg string, keysAndValues ...interface{}) {
+	l.log(LevelWarn, msg, keysAndValues)
+}
+
+func (l *Logger) Error(msg string, keysAndValues ...interface{}) {
+	l.log(LevelError, msg, keysAndValues)
+}
+
+// Log 按级别输出日志
+func (l *Logger) Log(level Level, msg string, keysAndValues ...interface{}) {
+	l.log(level, msg, keysAndValues)
+}
+
+func (l *Logger) log(level Level, msg string, keysAndValues []interface{}) {
+	if !l.Enabled(level) {
+		return
+	}
+
+	var buf bytes.Buffer
+	buf.WriteString(`{"time":`)
+	writeJSON(&buf, time.Now().Format(time.RFC3339Nano))
+	buf.WriteString(`,"level":`)
+	writeJSON(&buf, level.String())
+	buf.WriteString(`,"msg":`)
+	writeJSON(&buf, msg)
+	l.writeFields(&buf, l.fields)
+	l.writeFields(&buf, keysAndValues)
+	buf.WriteString("}\n")
+
+	l.out.mu.Lock()
+	defer l.out.mu.Unlock()
+	l.out.w.Write(buf.Bytes())
+}
+
+func (l *Logger) writeFields(buf *bytes.Buffer, keysAndValues []interface{}) {
+	for i := 0; i < len(keysAndValues); i += 2 {
+		key, ok := keysAndValues[i].(string)
+		if !ok {
+			key = fmt.Sprint(keysAndValues[i])
+		}
+		var value interface{} = "!MISSING"
+		if i+1 < len(keysAndValues) {
+			value = keysAndValues[i+1]
+		}
+
+		buf.WriteByte(',')
+		writeJSON(buf, key)
+		buf.WriteByte(':')
+		writeJSON(buf, l.sanitize(key, value))
+	}
+}
+
+// sanitize 将错误转为字符串，并对字段及其嵌套字段中的敏感值脱敏
+func (l *Logger) sanitize(key string, value interface{}) interface{} {
+	if l.redact[strings.ToLower(key)] {
+		return Redacted
+	}
+	switch v := value.(type) {
+	case nil, string, bool, int, int8, int16, int32, int64, uint, uint8, uint16, uint32, uint64, float32, float64:
+		return v
+	case time.Duration:
+		return v.String()
+	case error:
+		return v.Error()
+	case fmt.Stringer:
+		return v.String()
+	}
+
+	// 其他类型按 JSON 展开后逐层脱敏
+	data, err := json.Marshal(value)
+	if err != nil {
+		return fmt.Sprintf("%+v", value)
+	}
+	var decoded interface{}
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	if err := decoder.Decode(&decoded); err != nil {
+		return string(data)
+	}
+	return l.redactValue(decoded)
+}
+
+func (l *Logger) redactValue(value interface{}) interface{} {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		for key, item := range v {
+			if l.redact[strings.ToLower(key)] {
+				v[key] = Redacted
+			} else {
+				v[key] = l.redactValue(item)
+			}
+		}
+	case []interface{}:
+		for i, item := range v {
+			v[i] = l.redactValue(item)
+		}
+	}
+	return value
+}
+
+func writeJSON(buf *bytes.Buffer, value interface{}) {
+	data, err := json.Marshal(value)
+	if err != nil {
+		data, _ = json.Marshal(fmt.Sprint(value))
+	}
+	buf.Write(data)
+}
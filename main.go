@@ -1,8 +1,21 @@
 package main
 
 import (
+	"context"
+	"gin-swagger/counter"
 	"gin-swagger/dao"
 	docs "gin-swagger/docs"
+	"gin-swagger/health"
+	"gin-swagger/i18n"
+	"gin-swagger/idempotency"
+	"gin-swagger/logger"
+	"gin-swagger/middleware"
+	"gin-swagger/ratelimit"
+	"gin-swagger/search"
+	"gin-swagger/sitemap"
+	"gin-swagger/tracing"
+	"gin-swagger/trash"
+	"gin-swagger/validation"
 	"github.com/gin-gonic/gin"
 	"github.com/spf13/viper"
 	"os"
@@ -21,18 +34,42 @@ import (
 
 func main()  {
 	InitConfig()
+	logger.InitLogger()
+	tracing.InitTracer()
+	i18n.InitI18n()
+	validation.InitValidator()
 	dao.InitDB()
+	search.InitSearcher()
 
-	r := gin.Default()
+	if len(os.Args) > 1 {
+		RunCommand(os.Args[1:])
+		return
+	}
+
+	counter.InitViewCounter(dao.GetDB()).Start()
+	sitemap.InitGenerator(dao.GetDB())
+	trash.InitPurger(dao.GetDB()).Start()
+	ratelimit.InitLimiter()
+	idempotency.InitStore()
+	RegisterHealthChecks()
+
+	// 访问日志由 AccessLogMiddleware 以结构化格式记录；最外层的恢复兜底中间件自身的 panic
+	r := gin.New()
+	r.Use(middleware.RecoveryMiddleware())
 	docs.SwaggerInfo.BasePath = "/"
 
 	r = CollectRoute(r)
-	port := viper.GetString("server.port")
-	if port != "" {
-		panic(r.Run(":" + port))
-	}
-	panic(r.Run())
+	os.Exit(Serve(NewServer(r)))
+}
 
+// RegisterHealthChecks 注册就绪检查的依赖
+func RegisterHealthChecks() {
+	health.Register("database", health.DBChecker(dao.GetDB()))
+	health.Register("search", health.CheckerFunc(func(ctx context.Context) error {
+		return search.GetSearcher().Ping()
+	}))
+	health.Register("ratelimit", health.CheckerFunc(ratelimit.GetLimiter().Ping))
+	health.Register("idempotency", health.CheckerFunc(idempotency.GetStore().Ping))
 }
 
 func InitConfig()  {
@@ -0,0 +1,195 @@
+package markdown
+
+import (
+	"bytes"
+	"github.com/microcosm-cc/bluemonday"
+	"github.com/spf13/viper"
+	"github.com/yuin/goldmark"
+	"github.com/yuin/goldmark/ast"
+	"github.com/yuin/goldmark/extension"
+	"github.com/yuin/goldmark/parser"
+	"github.com/yuin/goldmark/text"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+const defaultExcerptLength = 200
+
+// Heading 目录条目
+type Heading struct {
+	Level int    `json:"level"`
+	ID    string `json:"id"`
+	Title string `json:"title"`
+}
+
+// Rendered Markdown 渲染结果
+type Rendered struct {
+	HTML    string
+	Excerpt string
+	TOC     []Heading
+}
+
+var md = goldmark.New(
+	goldmark.WithExtensions(extension.GFM),
+	goldmark.WithParserOptions(parser.WithAutoHeadingID()),
+)
+
+// policy 白名单过滤策略，在 UGC 策略基础上保留标题锚点和代码语言标记
+var policy = newPolicy()
+
+func newPolicy() *bluemonday.Policy {
+	p := bluemonday.UGCPolicy()
+	p.AllowAttrs("id").Matching(regexp.MustCompile(`^[\p{L}\p{N}_-]+$`)).OnElements("h1", "h2", "h3", "h4", "h5", "h6")
+	p.AllowAttrs("class").Matching(regexp.MustCompile(`^language-[\w-]+$`)).OnElements("code")
+	return p
+}
+
+// Render 将 Markdown 渲染为过滤后的 HTML，并生成纯文本摘要和目录
+func Render(source string) (Rendered, error) {
+	src := []byte(source)
+	doc := parse(src)
+
+	var buf bytes.Buffer
+	if err := md.Renderer().Render(&buf, src, doc); err != nil {
+		return Rendered{}, err
+	}
+
+	return Rendered{
+		HTML:    policy.Sanitize(buf.String()),
+		Excerpt: excerpt(doc, src),
+		TOC:     toc(doc, src),
+	}, nil
+}
+
+// PlainText 提取 Markdown 中的全部文本，去除标记和内嵌 HTML
+func PlainText(source string) string {
+	src := []byte(source)
+	doc := parse(src)
+
+	var buf bytes.Buffer
+	for n := doc.FirstChild(); n != nil; n = n.NextSibling() {
+		writeText(&buf, n, src)
+		buf.WriteByte('\n')
+	}
+	return strings.TrimSpace(buf.String())
+}
+
+func parse(src []byte) ast.Node {
+	ctx := parser.NewContext(parser.WithIDs(&headingIDs{values: make(map[string]bool)}))
+	return md.Parser().Parse(text.NewReader(src), parser.WithContext(ctx))
+}
+
+// headingIDs 标题锚点生成器，保留中文等非 ASCII 字符，重复时追加序号
+type headingIDs struct {
+	values map[string]bool
+}
+
+func (s *headingIDs) Generate(value []byte, kind ast.NodeKind) []byte {
+	var b strings.Builder
+	dash := false
+	for _, r := range strings.ToLower(string(value)) {
+		if unicode.IsLetter(r) || unicode.IsNumber(r) || r == '_' {
+			b.WriteRune(r)
+			dash = false
+		} else if !dash && b.Len() > 0 {
+			b.WriteByte('-')
+			dash = true
+		}
+	}
+
+	id := strings.TrimSuffix(b.String(), "-")
+	if id == "" {
+		id = "heading"
+	}
+	unique := id
+	for i := 1; s.values[unique]; i++ {
+		unique = id + "-" + strconv.Itoa(i)
+	}
+	s.values[unique] = true
+	return []byte(unique)
+}
+
+func (s *headingIDs) Put(value []byte) {
+	s.values[string(value)] = true
+}
+
+// excerpt 取正文段落的纯文本，按配置长度截断
+func excerpt(doc ast.Node, src []byte) string {
+	length := viper.GetInt("markdown.excerpt_length")
+	if length <= 0 {
+		length = defaultExcerptLength
+	}
+
+	var buf bytes.Buffer
+	for n := doc.FirstChild(); n != nil && utf8.RuneCount(buf.Bytes()) <= length; n = n.NextSibling() {
+		switch n.Kind() {
+		case ast.KindHeading, ast.KindFencedCodeBlock, ast.KindCodeBlock, ast.KindHTMLBlock, ast.KindThematicBreak:
+			continue
+		}
+		writeText(&buf, n, src)
+		buf.WriteByte(' ')
+	}
+
+	plain := strings.Join(strings.Fields(buf.String()), " ")
+	if utf8.RuneCountInString(plain) <= length {
+		return plain
+	}
+	return string([]rune(plain)[:length]) + "…"
+}
+
+// toc 按文档顺序收集各级标题
+func toc(doc ast.Node, src []byte) []Heading {
+	headings := make([]Heading, 0)
+	_ = ast.Walk(doc, func(n ast.Node, entering bool) (ast.WalkStatus, error) {
+		heading, ok := n.(*ast.Heading)
+		if !entering || !ok {
+			return ast.WalkContinue, nil
+		}
+
+		var title bytes.Buffer
+		writeText(&title, heading, src)
+		item := Heading{Level: heading.Level, Title: strings.TrimSpace(title.String())}
+		if id, ok := heading.AttributeString("id"); ok {
+			if b, ok := id.([]byte); ok {
+				item.ID = string(b)
+			}
+		}
+		headings = append(headings, item)
+		return ast.WalkSkipChildren, nil
+	})
+	return headings
+}
+
+// writeText 写出节点下的文本内容，代码块按原样保留
+func writeText(buf *bytes.Buffer, node ast.Node, src []byte) {
+	_ = ast.Walk(node, func(n ast.Node, entering bool) (ast.WalkStatus, error) {
+		if !entering {
+			if n.Type() == ast.TypeBlock && n != node {
+				buf.WriteByte('\n')
+			}
+			return ast.WalkContinue, nil
+		}
+
+		switch v := n.(type) {
+		case *ast.Text:
+			buf.Write(v.Segment.Value(src))
+			if v.SoftLineBreak() || v.HardLineBreak() {
+				buf.WriteByte(' ')
+			}
+		case *ast.String:
+			buf.Write(v.Value)
+		case *ast.FencedCodeBlock, *ast.CodeBlock:
+			lines := n.Lines()
+			for i := 0; i < lines.Len(); i++ {
+				segment := lines.At(i)
+				buf.Write(segment.Value(src))
+			}
+		case *ast.RawHTML, *ast.HTMLBlock:
+			return ast.WalkSkipChildren, nil
+		}
+		return ast.WalkContinue, nil
+	})
+}
@@ -0,0 +1,76 @@
+package metrics
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"net/http"
+)
+
+// Registry 服务所有指标的注册表，包含 Go 运行时和进程指标
+var Registry = prometheus.NewRegistry()
+
+var factory = promauto.With(Registry)
+
+func init() {
+	Registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+	)
+}
+
+// HTTP 指标，route 为路由模板（如 /posts/:id），未匹配路由的请求记为 unmatched，避免按原始路径产生大量时间序列
+var (
+	HTTPRequests = factory.NewCounterVec(prometheus.CounterOpts{
+		Name: "http_requests_total",
+		Help: "Total number of HTTP requests by method, route template and status code.",
+	}, []string{"method", "route", "status"})
+	HTTPRequestDuration = factory.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "http_request_duration_seconds",
+		Help:    "HTTP request latency by method and route template.",
+		Buckets: prometheus.DefBuckets,
+	}, []string{"method", "route"})
+	HTTPRequestsInFlight = factory.NewGauge(prometheus.GaugeOpts{
+		Name: "http_requests_in_flight",
+		Help: "Number of HTTP requests currently being served.",
+	})
+	HTTPPanics = factory.NewCounterVec(prometheus.CounterOpts{
+		Name: "http_panics_total",
+		Help: "Total number of panics recovered while serving HTTP requests by method and route template.",
+	}, []string{"method", "route"})
+)
+
+// 数据库指标，连接池指标在 InstrumentDB 时注册
+var (
+	DBQueryDuration = factory.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "db_query_duration_seconds",
+		Help:    "Database statement latency by gorm operation and table.",
+		Buckets: []float64{.0005, .001, .0025, .005, .01, .025, .05, .1, .25, .5, 1, 2.5},
+	}, []string{"operation", "table"})
+	DBQueryErrors = factory.NewCounterVec(prometheus.CounterOpts{
+		Name: "db_query_errors_total",
+		Help: "Total number of failed database statements by gorm operation and table.",
+	}, []string{"operation", "table"})
+)
+
+// 业务指标
+var (
+	UserRegistrations = factory.NewCounter(prometheus.CounterOpts{
+		Name: "user_registrations_total",
+		Help: "Total number of registered users.",
+	})
+	UserLogins = factory.NewCounterVec(prometheus.CounterOpts{
+		Name: "user_logins_total",
+		Help: "Total number of login attempts by result (success or failure).",
+	}, []string{"result"})
+	PostsCreated = factory.NewCounter(prometheus.CounterOpts{
+		Name: "posts_created_total",
+		Help: "Total number of created posts, including imported ones.",
+	})
+)
+
+// Handler 以 Prometheus 文本格式输出指标
+func Handler() http.Handler {
+	return promhttp.HandlerFor(Registry, promhttp.HandlerOpts{})
+}
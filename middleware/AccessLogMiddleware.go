@@ -0,0 +1,57 @@
+package middleware
+
+import (
+	"gin-swagger/logger"
+	"gin-swagger/model"
+	"github.com/gin-gonic/gin"
+	"net/http"
+	"time"
+)
+
+// AccessLogMiddleware 请求结束后记录访问日志，包括路由、状态码、耗时和用户ID；
+// 5xx 记为 error，4xx 记为 warn，debug 级别时附带脱敏后的请求头
+func AccessLogMiddleware() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		start := time.Now()
+		path := ctx.Request.URL.Path
+
+		ctx.Next()
+
+		status := ctx.Writer.Status()
+		level := logger.LevelInfo
+		switch {
+		case status >= http.StatusInternalServerError:
+			level = logger.LevelError
+		case status >= http.StatusBadRequest:
+			level = logger.LevelWarn
+		}
+
+		log := logger.FromContext(ctx)
+		if !log.Enabled(level) {
+			return
+		}
+		fields := []interface{}{
+			"method", ctx.Request.Method,
+			"path", path,
+			"route", ctx.FullPath(),
+			"status", status,
+			"latency_ms", float64(time.Since(start).Microseconds()) / 1000,
+			"bytes", ctx.Writer.Size(),
+			"client_ip", ctx.ClientIP(),
+			"user_agent", ctx.Request.UserAgent(),
+		}
+		if query := ctx.Request.URL.Query(); len(query) > 0 {
+			fields = append(fields, "query", query)
+		}
+		if user, ok := ctx.Get("user"); ok {
+			fields = append(fields, "user_id", user.(model.User).ID)
+		}
+		if len(ctx.Errors) > 0 {
+			fields = append(fields, "error", ctx.Errors.Last().Error())
+		}
+		if log.Enabled(logger.LevelDebug) {
+			fields = append(fields, "headers", ctx.Request.Header)
+		}
+		log.Log(level, "request", fields...)
+	}
+}
@@ -0,0 +1,21 @@
+package middleware
+
+import (
+	"gin-swagger/errcode"
+	"gin-swagger/model"
+	"github.com/gin-gonic/gin"
+)
+
+// AdminMiddleware 管理员权限验证，需挂在 AuthMiddleware 之后
+func AdminMiddleware() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		user, exists := ctx.Get("user")
+		if !exists || !user.(model.User).IsAdmin {
+			ctx.Error(errcode.New(errcode.AdminRequired))
+			ctx.Abort()
+			return
+		}
+
+		ctx.Next()
+	}
+}
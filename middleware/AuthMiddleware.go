@@ -2,9 +2,9 @@ package middleware
 
 import (
 	"gin-swagger/dao"
+	"gin-swagger/errcode"
 	"gin-swagger/model"
 	"github.com/gin-gonic/gin"
-	"net/http"
 	"strings"
 )
 
@@ -25,29 +25,31 @@ func AuthMiddleware() gin.HandlerFunc {
 		tokenString := ctx.GetHeader("Authorization")
 
 		// validate token formate
-		if tokenString == "" || !strings.HasPrefix(tokenString, "Bearer") {
-			ctx.JSON(http.StatusUnauthorized, gin.H{"code": 401, "msg": "权限不足"})
+		if tokenString == "" || !strings.HasPrefix(tokenString, "Bearer ") {
+			ctx.Error(errcode.New(errcode.Unauthorized))
+			ctx.Abort()
+			return
 		}
 
 		tokenString = tokenString[7:]
 
 		token, claims, err := dao.ParseToken(tokenString)
 		if err != nil || !token.Valid {
-			ctx.JSON(http.StatusUnauthorized, gin.H{"code": 401, "msg": "权限不足"})
+			ctx.Error(errcode.New(errcode.Unauthorized))
 			ctx.Abort()
 			return
 		}
 
 		// 验证通过，获取Claims中的userID
 		userID := claims.UserID
-		DB := dao.GetDB()
+		DB := dao.GetDB().WithContext(ctx.Request.Context())
 		var user model.User
 		DB.First(&user, userID)
 
 
 		// 判断用户是否存在
 		if user.ID == 0 {
-			ctx.JSON(http.StatusUnauthorized, gin.H{"code": 401, "msg": "权限不足"})
+			ctx.Error(errcode.New(errcode.Unauthorized))
 			ctx.Abort()
 			return
 		}
@@ -0,0 +1,25 @@
+package middleware
+
+import (
+	"gin-swagger/logger"
+	"gin-swagger/tlsconfig"
+	"github.com/gin-gonic/gin"
+)
+
+// ServiceIdentityKey 上下文中客户端证书对应的服务身份
+const ServiceIdentityKey = "service_identity"
+
+// ClientIdentityMiddleware 启用 mTLS 时把已校验的客户端证书映射为服务身份写入上下文，并记录到请求日志；
+// 未提供证书的请求不做处理
+func ClientIdentityMiddleware() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		state := ctx.Request.TLS
+		// VerifiedChains 非空说明证书已通过 client_ca_file 校验
+		if state != nil && len(state.VerifiedChains) > 0 && len(state.PeerCertificates) > 0 {
+			identity := tlsconfig.Identity(state.PeerCertificates[0])
+			ctx.Set(ServiceIdentityKey, identity)
+			ctx.Set(logger.ContextKey, logger.FromContext(ctx).With("service_identity", identity))
+		}
+		ctx.Next()
+	}
+}
@@ -0,0 +1,18 @@
+package middleware
+
+import (
+	"gin-swagger/response"
+	"github.com/gin-gonic/gin"
+)
+
+// ErrorMiddleware 统一渲染处理链中通过 ctx.Error 返回的错误，已写出响应时不再处理
+func ErrorMiddleware() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		ctx.Next()
+
+		if len(ctx.Errors) == 0 || ctx.Writer.Written() {
+			return
+		}
+		response.Error(ctx, ctx.Errors.Last().Err)
+	}
+}
@@ -0,0 +1,205 @@
+package middleware
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"gin-swagger/errcode"
+	"gin-swagger/idempotency"
+	"gin-swagger/logger"
+	"gin-swagger/model"
+	"gin-swagger/response"
+	"github.com/gin-gonic/gin"
+	"github.com/spf13/viper"
+	"io/ioutil"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// IdempotencyKeyHeader 客户端为可重试的请求生成的唯一键
+const IdempotencyKeyHeader = "Idempotency-Key"
+
+// IdempotentReplayedHeader 响应为重放已记录的结果时为 true
+const IdempotentReplayedHeader = "Idempotent-Replayed"
+
+const (
+	maxIdempotencyKeyLength    = 255
+	defaultIdempotencyTTL      = 24 * time.Hour
+	defaultIdempotencyLockTime = time.Minute
+)
+
+// replayHeaders 随响应一起记录并在重放时返回的响应头
+var replayHeaders = []string{"Content-Type", "Content-Language", "Location"}
+
+// recordingWriter 在写出响应的同时记录响应体
+type recordingWriter struct {
+	gin.ResponseWriter
+	body bytes.Buffer
+}
+
+func (w *recordingWriter) Write(data []byte) (int, error) {
+	w.body.Write(data)
+	return w.ResponseWriter.Write(data)
+}
+
+func (w *recordingWriter) WriteString(s string) (int, error) {
+	w.body.WriteString(s)
+	return w.ResponseWriter.WriteString(s)
+}
+
+// IdempotencyMiddleware 按 Idempotency-Key 请求头保证请求只执行一次：首次请求的响应在 idempotency.ttl 内保存，
+// 相同键和相同请求体的重试直接重放保存的响应；同一键用于不同请求体时返回 409，首次请求仍在处理时也返回 409 和 Retry-After。
+// 5xx 响应和 panic 不保存，客户端可用同一键重试。键按路由和用户隔离，未登录时按客户端IP隔离，需放在 AuthMiddleware 之后；未带请求头时不做处理
+func IdempotencyMiddleware() gin.HandlerFunc {
+	ttl := viper.GetDuration("idempotency.ttl")
+	if ttl <= 0 {
+		ttl = defaultIdempotencyTTL
+	}
+	// 处理中的记录在 lock_timeout 后过期，避免进程异常退出后键一直不可用
+	lockTimeout := viper.GetDuration("idempotency.lock_timeout")
+	if lockTimeout <= 0 {
+		lockTimeout = defaultIdempotencyLockTime
+	}
+
+	return func(ctx *gin.Context) {
+		key := ctx.GetHeader(IdempotencyKeyHeader)
+		store := idempotency.GetStore()
+		if key == "" || store == nil {
+			ctx.Next()
+			return
+		}
+		if !validIdempotencyKey(key) {
+			ctx.Error(errcode.New(errcode.InvalidIdempotencyKey, maxIdempotencyKeyLength))
+			ctx.Abort()
+			return
+		}
+
+		body, err := ioutil.ReadAll(ctx.Request.Body)
+		if err != nil {
+			ctx.Error(errcode.Wrap(err, errcode.InvalidParams))
+			ctx.Abort()
+			return
+		}
+		ctx.Request.Body = ioutil.NopCloser(bytes.NewReader(body))
+
+		log := logger.FromContext(ctx)
+		storeKey := idempotencyStoreKey(ctx, key)
+		fingerprint := requestFingerprint(ctx, body)
+		record, acquired, err := store.Begin(ctx.Request.Context(), storeKey, fingerprint, lockTimeout)
+		if err != nil {
+			// 存储不可用时照常处理，避免影响正常请求
+			log.Error("idempotency store failed", "error", err)
+			ctx.Next()
+			return
+		}
+
+		if !acquired {
+			switch {
+			case record.Fingerprint != fingerprint:
+				ctx.Error(errcode.New(errcode.IdempotencyKeyReused))
+			case !record.Completed:
+				ctx.Header("Retry-After", "1")
+				ctx.Error(errcode.New(errcode.IdempotencyInProgress))
+			default:
+				replay(ctx, record)
+			}
+			ctx.Abort()
+			return
+		}
+
+		completed := false
+		defer func() {
+			if completed {
+				return
+			}
+			// 请求已结束，不使用可能已取消的请求上下文
+			if err := store.Release(context.Background(), storeKey, fingerprint); err != nil {
+				log.Error("release idempotency key failed", "error", err)
+			}
+		}()
+
+		writer := &recordingWriter{ResponseWriter: ctx.Writer}
+		ctx.Writer = writer
+		ctx.Next()
+
+		// 处理函数通过 ctx.Error 返回的错误在此渲染，才能记录下来；ErrorMiddleware 不会重复渲染。
+		// 既没有写出响应也没有返回错误时无法判断是否成功，不保存，客户端可用同一键重试
+		if !writer.Written() {
+			if len(ctx.Errors) == 0 {
+				log.Warn("handler wrote no response, idempotency record not saved", "route", ctx.FullPath())
+				return
+			}
+			response.Error(ctx, ctx.Errors.Last().Err)
+		}
+		status := writer.Status()
+		if status >= http.StatusInternalServerError {
+			return
+		}
+
+		record = &idempotency.Record{
+			Fingerprint: fingerprint,
+			Completed:   true,
+			Status:      status,
+			Header:      http.Header{},
+			Body:        writer.body.Bytes(),
+		}
+		for _, name := range replayHeaders {
+			if value := writer.Header().Get(name); value != "" {
+				record.Header.Set(name, value)
+			}
+		}
+		if err := store.Complete(context.Background(), storeKey, record, ttl); err != nil {
+			log.Error("save idempotency record failed", "error", err)
+			return
+		}
+		completed = true
+	}
+}
+
+// replay 重放已记录的响应
+func replay(ctx *gin.Context, record *idempotency.Record) {
+	for name, values := range record.Header {
+		for _, value := range values {
+			ctx.Writer.Header().Add(name, value)
+		}
+	}
+	ctx.Header(IdempotentReplayedHeader, strconv.FormatBool(true))
+	ctx.Status(record.Status)
+	ctx.Writer.Write(record.Body)
+}
+
+// idempotencyStoreKey 按方法、路由和客户端隔离幂等键，登录用户按用户ID，未登录时按客户端IP，存储中只保存摘要
+func idempotencyStoreKey(ctx *gin.Context, key string) string {
+	scope := ctx.Request.Method + " " + ctx.FullPath() + " "
+	if user, ok := ctx.Get("user"); ok {
+		scope += "user:" + strconv.Itoa(int(user.(model.User).ID))
+	} else {
+		scope += "ip:" + ctx.ClientIP()
+	}
+	sum := sha256.Sum256([]byte(scope + " " + key))
+	return hex.EncodeToString(sum[:])
+}
+
+// requestFingerprint 请求的路径、查询参数和请求体摘要，用于识别同一键用于不同请求
+func requestFingerprint(ctx *gin.Context, body []byte) string {
+	hash := sha256.New()
+	hash.Write([]byte(ctx.Request.URL.RequestURI()))
+	hash.Write([]byte{0})
+	hash.Write(body)
+	return hex.EncodeToString(hash.Sum(nil))
+}
+
+// validIdempotencyKey 只接受长度有限的可见 ASCII 字符
+func validIdempotencyKey(key string) bool {
+	if len(key) > maxIdempotencyKeyLength {
+		return false
+	}
+	for i := 0; i < len(key); i++ {
+		if key[i] < 0x21 || key[i] > 0x7e {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,18 @@
+package middleware
+
+import (
+	"gin-swagger/i18n"
+	"github.com/gin-gonic/gin"
+)
+
+// LocaleMiddleware 按 Accept-Language 协商响应语言并写入上下文
+func LocaleMiddleware() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		lang := i18n.Negotiate(ctx.GetHeader("Accept-Language"))
+		ctx.Set(i18n.ContextKey, lang)
+		ctx.Header("Content-Language", lang)
+		ctx.Writer.Header().Add("Vary", "Accept-Language")
+
+		ctx.Next()
+	}
+}
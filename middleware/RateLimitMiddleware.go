@@ -0,0 +1,85 @@
+package middleware
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"gin-swagger/errcode"
+	"gin-swagger/logger"
+	"gin-swagger/model"
+	"gin-swagger/ratelimit"
+	"github.com/gin-gonic/gin"
+	"github.com/spf13/viper"
+	"math"
+	"strconv"
+	"time"
+)
+
+// RateLimitMiddleware 按配置 ratelimit.policies 中名为 name 的策略限流，未配置该策略时不限流；
+// 响应中带 RateLimit-* 头，超出配额时返回 429 和 Retry-After。按用户限流时需放在 AuthMiddleware 之后
+func RateLimitMiddleware(name string) gin.HandlerFunc {
+	limiter := ratelimit.GetLimiter()
+	if limiter == nil {
+		return func(ctx *gin.Context) { ctx.Next() }
+	}
+	policy, ok := limiter.Policy(name)
+	if !ok {
+		return func(ctx *gin.Context) { ctx.Next() }
+	}
+
+	policyHeader := strconv.Itoa(policy.Limit) + ";w=" + strconv.Itoa(int(policy.Period.Seconds()))
+	if policy.Algorithm == ratelimit.TokenBucket {
+		policyHeader += ";burst=" + strconv.Itoa(policy.Burst)
+	}
+
+	return func(ctx *gin.Context) {
+		result, err := limiter.Allow(ctx.Request.Context(), policy, rateLimitKey(ctx, policy))
+		if err != nil {
+			// 存储不可用时放行，避免影响正常请求
+			logger.FromContext(ctx).Error("rate limit store failed", "policy", policy.Name, "error", err)
+			ctx.Next()
+			return
+		}
+
+		ctx.Header("RateLimit-Limit", strconv.Itoa(result.Limit))
+		ctx.Header("RateLimit-Remaining", strconv.Itoa(result.Remaining))
+		ctx.Header("RateLimit-Reset", strconv.Itoa(ceilSeconds(result.Reset)))
+		ctx.Header("RateLimit-Policy", policyHeader)
+		if !result.Allowed {
+			retryAfter := ceilSeconds(result.RetryAfter)
+			if retryAfter < 1 {
+				retryAfter = 1
+			}
+			ctx.Header("Retry-After", strconv.Itoa(retryAfter))
+			ctx.Error(errcode.New(errcode.TooManyRequests, retryAfter))
+			ctx.Abort()
+			return
+		}
+
+		ctx.Next()
+	}
+}
+
+// rateLimitKey 按策略取限流键，未登陆或未带 API Key 时退回按IP限流
+func rateLimitKey(ctx *gin.Context, policy ratelimit.Policy) string {
+	switch policy.Key {
+	case ratelimit.KeyUser:
+		if user, ok := ctx.Get("user"); ok {
+			return "user:" + strconv.Itoa(int(user.(model.User).ID))
+		}
+	case ratelimit.KeyAPIKey:
+		header := viper.GetString("ratelimit.api_key_header")
+		if header == "" {
+			header = "X-API-Key"
+		}
+		// 存储中只保存 API Key 的摘要
+		if apiKey := ctx.GetHeader(header); apiKey != "" {
+			sum := sha256.Sum256([]byte(apiKey))
+			return "key:" + hex.EncodeToString(sum[:16])
+		}
+	}
+	return "ip:" + ctx.ClientIP()
+}
+
+func ceilSeconds(d time.Duration) int {
+	return int(math.Ceil(d.Seconds()))
+}
@@ -0,0 +1,165 @@
+package middleware
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"gin-swagger/errcode"
+	"gin-swagger/logger"
+	"gin-swagger/metrics"
+	"gin-swagger/model"
+	"gin-swagger/response"
+	"github.com/gin-gonic/gin"
+	"net"
+	"net/http"
+	"os"
+	"runtime/debug"
+	"strings"
+	"sync"
+)
+
+// PanicReport 一次 panic 的上下文，交给 PanicReporter 上报到错误追踪服务
+type PanicReport struct {
+	Value     interface{}
+	Stack     []byte
+	RequestID string
+	Method    string
+	Path      string
+	Route     string
+	// UserID 未登录时为 0
+	UserID uint
+}
+
+// PanicReporter 上报 panic，在处理请求的协程中同步调用，耗时的上报应自行异步发送
+type PanicReporter func(ctx *gin.Context, report PanicReport)
+
+var (
+	reportersMu sync.RWMutex
+	reporters   []PanicReporter
+)
+
+// RegisterPanicReporter 注册 panic 上报，可注册多个，按注册顺序调用
+func RegisterPanicReporter(reporter PanicReporter) {
+	reportersMu.Lock()
+	defer reportersMu.Unlock()
+	reporters = append(reporters, reporter)
+}
+
+// RecoveryMiddleware 从处理链的 panic 中恢复，记录带路由、用户和堆栈的错误日志，计入 http_panics_total，
+// 调用已注册的 PanicReporter，并以统一的错误响应（含请求ID）返回 500；客户端已断开连接时只记录日志
+func RecoveryMiddleware() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		defer func() {
+			value := recover()
+			if value == nil {
+				return
+			}
+			// 与 net/http 约定一致，ErrAbortHandler 用于静默中止响应，交给 http.Server 处理
+			if value == http.ErrAbortHandler {
+				panic(value)
+			}
+
+			report := PanicReport{
+				Value:     value,
+				Stack:     debug.Stack(),
+				RequestID: logger.RequestID(ctx),
+				Method:    ctx.Request.Method,
+				Path:      ctx.Request.URL.Path,
+				Route:     ctx.FullPath(),
+			}
+			if user, ok := ctx.Get("user"); ok {
+				if u, ok := user.(model.User); ok {
+					report.UserID = u.ID
+				}
+			}
+
+			log := logger.FromContext(ctx)
+			if brokenPipe(value) {
+				log.Warn("client connection closed", "method", report.Method, "path", report.Path, "error", value)
+				ctx.Abort()
+				return
+			}
+
+			route := report.Route
+			if route == "" {
+				route = "unmatched"
+			}
+			metrics.HTTPPanics.WithLabelValues(report.Method, route).Inc()
+			log.Error("panic recovered",
+				"method", report.Method,
+				"path", report.Path,
+				"route", report.Route,
+				"user_id", report.UserID,
+				"panic", fmt.Sprint(value),
+				"stack", string(report.Stack),
+			)
+			notify(ctx, report)
+
+			// 中止后续处理，外层中间件的 Next 不再继续执行剩余的处理函数
+			ctx.Abort()
+			if ctx.Writer.Written() {
+				return
+			}
+			err := errcode.Wrap(fmt.Errorf("panic: %v", value), errcode.Internal)
+			ctx.Error(err)
+			renderPanic(ctx, err)
+		}()
+
+		ctx.Next()
+	}
+}
+
+// renderPanic 以统一的错误格式返回 500；渲染本身再次 panic 时改为返回不经过格式协商的固定 JSON
+func renderPanic(ctx *gin.Context, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			logger.FromContext(ctx).Error("render panic response failed", "panic", fmt.Sprint(r))
+			if ctx.Writer.Written() {
+				return
+			}
+			envelope := gin.H{"code": http.StatusInternalServerError, "error": errcode.Internal, "data": nil}
+			if requestID := logger.RequestID(ctx); requestID != "" {
+				envelope["request_id"] = requestID
+			}
+			body, _ := json.Marshal(envelope)
+			ctx.Data(http.StatusInternalServerError, "application/json; charset=utf-8", body)
+		}
+	}()
+	response.Error(ctx, err)
+}
+
+// notify 依次调用 PanicReporter，单个上报出错不影响其他上报和响应
+func notify(ctx *gin.Context, report PanicReport) {
+	reportersMu.RLock()
+	list := reporters
+	reportersMu.RUnlock()
+
+	for _, reporter := range list {
+		func() {
+			defer func() {
+				if r := recover(); r != nil {
+					logger.FromContext(ctx).Error("panic reporter failed", "panic", fmt.Sprint(r))
+				}
+			}()
+			reporter(ctx, report)
+		}()
+	}
+}
+
+// brokenPipe 写响应时客户端已断开连接，此时无法再返回响应
+func brokenPipe(value interface{}) bool {
+	err, ok := value.(error)
+	if !ok {
+		return false
+	}
+	var opErr *net.OpError
+	if !errors.As(err, &opErr) {
+		return false
+	}
+	var syscallErr *os.SyscallError
+	if !errors.As(opErr, &syscallErr) {
+		return false
+	}
+	msg := strings.ToLower(syscallErr.Error())
+	return strings.Contains(msg, "broken pipe") || strings.Contains(msg, "connection reset by peer")
+}
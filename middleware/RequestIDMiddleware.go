@@ -0,0 +1,43 @@
+package middleware
+
+import (
+	"gin-swagger/logger"
+	"github.com/gin-gonic/gin"
+	uuid "github.com/satori/go.uuid"
+)
+
+// RequestIDHeader 请求ID请求头和响应头
+const RequestIDHeader = "X-Request-ID"
+
+// maxRequestIDLength 客户端传入的请求ID最大长度
+const maxRequestIDLength = 128
+
+// RequestIDMiddleware 沿用客户端传入的 X-Request-ID，没有或不合法时生成一个，
+// 写入响应头并在上下文中放入带请求ID的日志
+func RequestIDMiddleware() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		requestID := ctx.GetHeader(RequestIDHeader)
+		if !validRequestID(requestID) {
+			requestID = uuid.NewV4().String()
+		}
+
+		ctx.Set(logger.RequestIDKey, requestID)
+		ctx.Set(logger.ContextKey, logger.GetLogger().With("request_id", requestID))
+		ctx.Header(RequestIDHeader, requestID)
+
+		ctx.Next()
+	}
+}
+
+// validRequestID 只接受长度有限的可见 ASCII 字符，避免日志注入
+func validRequestID(requestID string) bool {
+	if requestID == "" || len(requestID) > maxRequestIDLength {
+		return false
+	}
+	for i := 0; i < len(requestID); i++ {
+		if requestID[i] < 0x21 || requestID[i] > 0x7e {
+			return false
+		}
+	}
+	return true
+}
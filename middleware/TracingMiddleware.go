@@ -0,0 +1,30 @@
+package middleware
+
+import (
+	"gin-swagger/logger"
+	"gin-swagger/model"
+	"github.com/gin-gonic/gin"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// TracingMiddleware 在 otelgin 创建的请求 span 上补充请求ID和用户ID，并把 trace_id 写入请求日志；
+// 需放在 RequestIDMiddleware 之后
+func TracingMiddleware() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		span := trace.SpanFromContext(ctx.Request.Context())
+		if spanContext := span.SpanContext(); spanContext.IsValid() {
+			ctx.Set(logger.ContextKey, logger.FromContext(ctx).With("trace_id", spanContext.TraceID().String()))
+		}
+		if requestID := logger.RequestID(ctx); requestID != "" {
+			span.SetAttributes(attribute.String("http.request_id", requestID))
+		}
+
+		ctx.Next()
+
+		// 用户在 AuthMiddleware 中写入上下文，请求结束后才能取到
+		if user, ok := ctx.Get("user"); ok {
+			span.SetAttributes(attribute.Int64("enduser.id", int64(user.(model.User).ID)))
+		}
+	}
+}
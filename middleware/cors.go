@@ -1,47 +1,226 @@
 package middleware
 
 import (
-	"fmt"
 	"github.com/gin-gonic/gin"
+	"github.com/spf13/viper"
+	"net/http"
+	"regexp"
+	"sort"
+	"strconv"
 	"strings"
 )
 
+// corsPolicy 跨域策略，origins 支持完整来源、"*" 和 "https://*.example.com" 形式的子域通配，patterns 为来源正则
+type corsPolicy struct {
+	prefix           string
+	origins          []string
+	patterns         []*regexp.Regexp
+	allowMethods     []string
+	allowHeaders     []string
+	exposeHeaders    []string
+	allowCredentials bool
+	maxAge           int
+}
+
+// Cors 按配置 cors 处理跨域请求，cors.groups 下可按路径前缀覆盖部分配置；
+// 没有 Origin 请求头的请求不是跨域请求，直接放行
 func Cors() gin.HandlerFunc {
+	policies := loadCorsPolicies()
+
 	return func(c *gin.Context) {
-		method := c.Request.Method
-		origin := c.Request.Header.Get("Origin")
-		var headerKeys []string
-		for k, _ := range c.Request.Header {
-			headerKeys = append(headerKeys, k)
-		}
-		headerStr := strings.Join(headerKeys, ", ")
-		if headerStr != "" {
-			headerStr = fmt.Sprintf("access-control-allow-origin, access-control-allow-headers, %s", headerStr)
-		} else {
-			headerStr = "access-control-allow-origin, access-control-allow-headers"
-		}
-		if origin != "" {
-			c.Writer.Header().Set("Access-Control-Allow-Origin", "*")
-			c.Header("Access-Control-Allow-Origin", "*")                                       // 这是允许访问所有域
-			c.Header("Access-Control-Allow-Methods", "POST, GET, OPTIONS, PUT, DELETE,UPDATE") //服务器支持的所有跨域请求的方法,为了避免浏览次请求的多次'预检'请求
-			//  header的类型
-			c.Header("Access-Control-Allow-Headers", "Authorization, Content-Length, X-CSRF-Token, Token,session,X_Requested_With,Accept, Origin, Host, Connection, Accept-Encoding, Accept-Language,DNT, X-CustomHeader, Keep-Alive, User-Agent, X-Requested-With, If-Modified-Since, Cache-Control, Content-Type, Pragma")
-			//              允许跨域设置                                                                                                      可以返回其他子段
-			c.Header("Access-Control-Expose-Headers", "Content-Length, Access-Control-Allow-Origin, Access-Control-Allow-Headers,Cache-Control,Content-Language,Content-Type,Expires,Last-Modified,Pragma,FooBar") // 跨域关键设置 让浏览器可以解析
-			c.Header("Access-Control-Max-Age", "172800")                                                                                                                                                           // 缓存请求信息 单位为秒
-			c.Header("Access-Control-Allow-Credentials", "false")                                                                                                                                                  //  跨域请求是否需要带cookie信息 默认设置为true
-			c.Set("content-type", "application/json")                                                                                                                                                              // 设置返回格式是json
-		}
-
-		//放行所有OPTIONS方法
-		//if method == "OPTIONS" {
-		//    c.JSON(http.StatusOK, "Options Request!")
-		//}
-		if method == "OPTIONS" {
-			c.AbortWithStatus(204)
+		origin := c.GetHeader("Origin")
+		if origin == "" {
+			c.Next()
 			return
 		}
-		// 处理请求
-		c.Next() //  处理请求
+
+		policy := matchCorsPolicy(policies, c.Request.URL.Path)
+		allowed := policy.allowOrigin(origin)
+		// 响应随 Origin 变化时告知缓存
+		if !policy.anyOrigin() || policy.allowCredentials {
+			c.Writer.Header().Add("Vary", "Origin")
+		}
+
+		// 预检请求：校验来源、方法和请求头，不通过时返回 403 且不带跨域响应头
+		requestMethod := c.GetHeader("Access-Control-Request-Method")
+		if c.Request.Method == http.MethodOptions && requestMethod != "" {
+			c.Writer.Header().Add("Vary", "Access-Control-Request-Method")
+			c.Writer.Header().Add("Vary", "Access-Control-Request-Headers")
+			requestHeaders := splitHeaderList(c.GetHeader("Access-Control-Request-Headers"))
+			if !allowed || !policy.allowMethod(requestMethod) || !policy.allowHeadersOf(requestHeaders) {
+				c.AbortWithStatus(http.StatusForbidden)
+				return
+			}
+
+			policy.writeOrigin(c, origin)
+			c.Header("Access-Control-Allow-Methods", strings.Join(policy.allowMethods, ", "))
+			if len(requestHeaders) > 0 {
+				c.Header("Access-Control-Allow-Headers", strings.Join(requestHeaders, ", "))
+			}
+			if policy.maxAge > 0 {
+				c.Header("Access-Control-Max-Age", strconv.Itoa(policy.maxAge))
+			}
+			c.AbortWithStatus(http.StatusNoContent)
+			return
+		}
+
+		// 普通跨域请求，来源不允许时不返回跨域响应头，由浏览器拦截
+		if allowed {
+			policy.writeOrigin(c, origin)
+			if len(policy.exposeHeaders) > 0 {
+				c.Header("Access-Control-Expose-Headers", strings.Join(policy.exposeHeaders, ", "))
+			}
+		}
+		c.Next()
+	}
+}
+
+// loadCorsPolicies 读取默认策略和各路由组策略，路由组按前缀从长到短排列，默认策略在最后
+func loadCorsPolicies() []corsPolicy {
+	base := loadCorsPolicy("cors", corsPolicy{
+		allowMethods: []string{http.MethodGet, http.MethodPost, http.MethodPut, http.MethodDelete},
+	})
+
+	var policies []corsPolicy
+	for name := range viper.GetStringMap("cors.groups") {
+		key := "cors.groups." + name
+		policy := loadCorsPolicy(key, base)
+		policy.prefix = viper.GetString(key + ".prefix")
+		if policy.prefix == "" {
+			panic("cors group " + name + " requires prefix")
+		}
+		policies = append(policies, policy)
+	}
+	sort.Slice(policies, func(i, j int) bool {
+		return len(policies[i].prefix) > len(policies[j].prefix)
+	})
+	return append(policies, base)
+}
+
+// loadCorsPolicy 读取 key 下的策略，未配置的项沿用 base
+func loadCorsPolicy(key string, base corsPolicy) corsPolicy {
+	policy := base
+	if viper.IsSet(key + ".allow_origins") {
+		policy.origins = viper.GetStringSlice(key + ".allow_origins")
+	}
+	if viper.IsSet(key + ".allow_origin_patterns") {
+		policy.patterns = nil
+		for _, pattern := range viper.GetStringSlice(key + ".allow_origin_patterns") {
+			re, err := regexp.Compile(pattern)
+			if err != nil {
+				panic("invalid cors origin pattern, err: " + err.Error())
+			}
+			policy.patterns = append(policy.patterns, re)
+		}
+	}
+	if viper.IsSet(key + ".allow_methods") {
+		policy.allowMethods = viper.GetStringSlice(key + ".allow_methods")
+		for i, method := range policy.allowMethods {
+			policy.allowMethods[i] = strings.ToUpper(method)
+		}
+	}
+	if viper.IsSet(key + ".allow_headers") {
+		policy.allowHeaders = viper.GetStringSlice(key + ".allow_headers")
+	}
+	if viper.IsSet(key + ".expose_headers") {
+		policy.exposeHeaders = viper.GetStringSlice(key + ".expose_headers")
+	}
+	if viper.IsSet(key + ".allow_credentials") {
+		policy.allowCredentials = viper.GetBool(key + ".allow_credentials")
+	}
+	if viper.IsSet(key + ".max_age") {
+		policy.maxAge = int(viper.GetDuration(key + ".max_age").Seconds())
+	}
+	return policy
+}
+
+// matchCorsPolicy 选择前缀最长的路由组策略，没有匹配时使用默认策略
+func matchCorsPolicy(policies []corsPolicy, path string) corsPolicy {
+	for _, policy := range policies[:len(policies)-1] {
+		if path == policy.prefix || strings.HasPrefix(path, strings.TrimSuffix(policy.prefix, "/")+"/") {
+			return policy
+		}
+	}
+	return policies[len(policies)-1]
+}
+
+func (p corsPolicy) anyOrigin() bool {
+	for _, allowed := range p.origins {
+		if allowed == "*" {
+			return true
+		}
+	}
+	return false
+}
+
+func (p corsPolicy) allowOrigin(origin string) bool {
+	for _, allowed := range p.origins {
+		if allowed == "*" || strings.EqualFold(allowed, origin) {
+			return true
+		}
+		// 子域通配：https://*.example.com 匹配 https://a.example.com，不匹配 https://example.com
+		if i := strings.Index(allowed, "*."); i >= 0 {
+			scheme, domain := allowed[:i], allowed[i+1:]
+			if len(origin) > len(scheme)+len(domain) &&
+				strings.EqualFold(origin[:len(scheme)], scheme) &&
+				strings.HasSuffix(strings.ToLower(origin), strings.ToLower(domain)) &&
+				!strings.Contains(origin[len(scheme):len(origin)-len(domain)], "/") {
+				return true
+			}
+		}
+	}
+	for _, re := range p.patterns {
+		if re.MatchString(origin) {
+			return true
+		}
+	}
+	return false
+}
+
+func (p corsPolicy) allowMethod(method string) bool {
+	for _, allowed := range p.allowMethods {
+		if allowed == method {
+			return true
+		}
+	}
+	return false
+}
+
+// allowHeadersOf 预检请求的每个请求头都必须在允许列表中，允许列表含 "*" 时不限制
+func (p corsPolicy) allowHeadersOf(headers []string) bool {
+	for _, header := range headers {
+		ok := false
+		for _, allowed := range p.allowHeaders {
+			if allowed == "*" || strings.EqualFold(allowed, header) {
+				ok = true
+				break
+			}
+		}
+		if !ok {
+			return false
+		}
+	}
+	return true
+}
+
+// writeOrigin 允许携带凭证时必须回显具体来源，不能使用 "*"
+func (p corsPolicy) writeOrigin(c *gin.Context, origin string) {
+	if p.anyOrigin() && !p.allowCredentials {
+		c.Header("Access-Control-Allow-Origin", "*")
+		return
+	}
+	c.Header("Access-Control-Allow-Origin", origin)
+	if p.allowCredentials {
+		c.Header("Access-Control-Allow-Credentials", "true")
+	}
+}
+
+func splitHeaderList(value string) []string {
+	var headers []string
+	for _, header := range strings.Split(value, ",") {
+		if header = strings.TrimSpace(header); header != "" {
+			headers = append(headers, header)
+		}
 	}
+	return headers
 }
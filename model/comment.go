@@ -0,0 +1,28 @@
+package model
+
+import (
+	uuid "github.com/satori/go.uuid"
+	"gorm.io/gorm"
+)
+
+// 评论审核状态
+const (
+	CommentStatusPending  = "pending"
+	CommentStatusApproved = "approved"
+	CommentStatusSpam     = "spam"
+)
+
+// Comment 评论结构体，RootID 指向所在楼层的顶层评论，便于整楼加载
+type Comment struct {
+	ID uint `json:"id" form:"id" gorm:"primary_key"`
+	PostID uuid.UUID `json:"post_id" form:"post_id" gorm:"type:char(36);not null;index"`
+	UserID uint `json:"user_id" form:"user_id" gorm:"not null"`
+	ParentID *uint `json:"parent_id" form:"parent_id" gorm:"index"`
+	RootID *uint `json:"root_id" form:"root_id" gorm:"index"`
+	Content string `json:"content" form:"content" gorm:"type:text;not null"`
+	Status string `json:"status" form:"status" gorm:"type:varchar(20);not null;default:pending;index"`
+	Replies []*Comment `json:"replies,omitempty" gorm:"-"`
+	CreatedAt Time `json:"created_at" form:"created_at" gorm:"type:timestamp"`
+	UpdatedAt Time `json:"updated_at" form:"updated_at" gorm:"type:timestamp"`
+	DeletedAt gorm.DeletedAt `json:"-" gorm:"index"`
+}
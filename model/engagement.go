@@ -0,0 +1,18 @@
+package model
+
+import uuid "github.com/satori/go.uuid"
+
+// PostLike 文章点赞记录，用户与文章联合主键保证幂等
+type PostLike struct {
+	UserID uint `json:"user_id" gorm:"primaryKey;autoIncrement:false"`
+	PostID uuid.UUID `json:"post_id" gorm:"type:char(36);primaryKey"`
+	CreatedAt Time `json:"created_at" gorm:"type:timestamp"`
+}
+
+// PostBookmark 文章收藏记录
+type PostBookmark struct {
+	UserID uint `json:"user_id" gorm:"primaryKey;autoIncrement:false"`
+	PostID uuid.UUID `json:"post_id" gorm:"type:char(36);primaryKey;index"`
+	Post *Post `json:"post,omitempty"`
+	CreatedAt Time `json:"created_at" gorm:"type:timestamp"`
+}
@@ -1,24 +1,54 @@
 package model
 
 import (
+	"gin-swagger/markdown"
 	uuid "github.com/satori/go.uuid"
 	"gorm.io/gorm"
 )
 
+// 文章发布状态
+const (
+	PostStatusDraft     = "draft"
+	PostStatusPublished = "published"
+)
+
 // Post 文章结构体
 type Post struct {
 	ID uuid.UUID `json:"id" form:"id" gorm:"type:char(36);primary_key"`
 	UserID uint `json:"user_id" form:"user_id" gorm:"not null"`
 	CategoryID uint `json:"category_id" form:"category_id" gorm:"not null"`
-	Category *Category
+	Category *Category `json:"category,omitempty"`
+	Tags []Tag `json:"tags,omitempty" gorm:"many2many:post_tags"`
 	Title string `json:"title" form:"title" gorm:"type:varchar(50);not null"`
 	HeadImg string `json:"head_img" form:"head_img"`
 	Content string `json:"content" form:"content" gorm:"type:text;not null"`
+	ContentHTML string `json:"content_html" form:"-" gorm:"type:mediumtext"`
+	Excerpt string `json:"excerpt" form:"-" gorm:"type:varchar(1000)"`
+	TOC TOC `json:"toc" form:"-" gorm:"type:text"`
+	Status string `json:"status" form:"status" gorm:"type:varchar(20);not null;default:published;index"`
+	CommentsDisabled bool `json:"comments_disabled" form:"comments_disabled" gorm:"not null;default:false"`
+	LikeCount int64 `json:"like_count" form:"-" gorm:"not null;default:0"`
+	BookmarkCount int64 `json:"bookmark_count" form:"-" gorm:"not null;default:0"`
+	ViewCount int64 `json:"view_count" form:"-" gorm:"not null;default:0"`
 	CreatedAt Time `json:"created_at" form:"created_at" gorm:"type:timestamp"`
 	UpdatedAt Time `json:"updated_at" form:"updated_at" gorm:"type:timestamp"`
+	DeletedAt gorm.DeletedAt `json:"deleted_at" form:"-" gorm:"index"`
 }
 
 func (post *Post) BeforeCreate(db *gorm.DB) error {
 	post.ID = uuid.NewV4()
 	return nil
+}
+
+// RenderContent 将 Markdown 正文渲染为过滤后的 HTML，并生成摘要和目录
+func (post *Post) RenderContent() error {
+	rendered, err := markdown.Render(post.Content)
+	if err != nil {
+		return err
+	}
+
+	post.ContentHTML = rendered.HTML
+	post.Excerpt = rendered.Excerpt
+	post.TOC = rendered.TOC
+	return nil
 }
\ No newline at end of file
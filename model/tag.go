@@ -0,0 +1,9 @@
+package model
+
+// Tag 标签结构体
+type Tag struct {
+	ID uint `json:"id" form:"id" gorm:"primary_key"`
+	Name string `json:"name" form:"name" gorm:"type:varchar(30);not null;unique"`
+	CreatedAt Time `json:"created_at" form:"created_at" gorm:"type:timestamp"`
+	UpdatedAt Time `json:"updated_at" form:"updated_at" gorm:"type:timestamp"`
+}
@@ -0,0 +1,32 @@
+package model
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+	"gin-swagger/markdown"
+)
+
+// TOC 文章目录，以 JSON 形式存储
+type TOC []markdown.Heading
+
+func (t TOC) Value() (driver.Value, error) {
+	if t == nil {
+		return "[]", nil
+	}
+	b, err := json.Marshal(t)
+	return string(b), err
+}
+
+func (t *TOC) Scan(v interface{}) error {
+	switch value := v.(type) {
+	case []byte:
+		return json.Unmarshal(value, t)
+	case string:
+		return json.Unmarshal([]byte(value), t)
+	case nil:
+		*t = nil
+		return nil
+	}
+	return fmt.Errorf("can not convert %v to toc", v)
+}
@@ -0,0 +1,113 @@
+package ratelimit
+
+import (
+	"context"
+	"math"
+	"sync"
+	"time"
+)
+
+// sweepInterval 内存存储清理过期状态的间隔
+const sweepInterval = time.Minute
+
+type bucket struct {
+	tokens  float64
+	updated time.Time
+	expires time.Time
+}
+
+type windowLog struct {
+	hits    []time.Time
+	expires time.Time
+}
+
+// MemoryStore 单实例使用的内存存储，过期状态在访问时定期清理
+type MemoryStore struct {
+	mu        sync.Mutex
+	buckets   map[string]*bucket
+	windows   map[string]*windowLog
+	lastSweep time.Time
+}
+
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{
+		buckets:   make(map[string]*bucket),
+		windows:   make(map[string]*windowLog),
+		lastSweep: time.Now(),
+	}
+}
+
+func (s *MemoryStore) TokenBucket(ctx context.Context, key string, capacity int, rate float64, now time.Time) (Result, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.sweep(now)
+
+	b, ok := s.buckets[key]
+	if !ok {
+		b = &bucket{tokens: float64(capacity), updated: now}
+		s.buckets[key] = b
+	}
+	if elapsed := now.Sub(b.updated).Seconds(); elapsed > 0 {
+		b.tokens = math.Min(float64(capacity), b.tokens+elapsed*rate)
+		b.updated = now
+	}
+
+	allowed := b.tokens >= 1
+	if allowed {
+		b.tokens--
+	}
+	// 桶补满后状态与新建时相同，可以清理
+	b.expires = now.Add(seconds((float64(capacity) - b.tokens) / rate))
+	return bucketResult(allowed, b.tokens, capacity, rate), nil
+}
+
+func (s *MemoryStore) SlidingWindow(ctx context.Context, key string, limit int, window time.Duration, now time.Time) (Result, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.sweep(now)
+
+	w, ok := s.windows[key]
+	if !ok {
+		w = &windowLog{}
+		s.windows[key] = w
+	}
+	// 去掉窗口外的请求
+	start := now.Add(-window)
+	i := 0
+	for i < len(w.hits) && !w.hits[i].After(start) {
+		i++
+	}
+	w.hits = w.hits[i:]
+
+	allowed := len(w.hits) < limit
+	if allowed {
+		w.hits = append(w.hits, now)
+	}
+	w.expires = w.hits[len(w.hits)-1].Add(window)
+	return windowResult(allowed, len(w.hits), w.hits[0], limit, window, now), nil
+}
+
+func (s *MemoryStore) Ping(ctx context.Context) error {
+	return nil
+}
+
+func (s *MemoryStore) Close() error {
+	return nil
+}
+
+func (s *MemoryStore) sweep(now time.Time) {
+	if now.Sub(s.lastSweep) < sweepInterval {
+		return
+	}
+	s.lastSweep = now
+	for key, b := range s.buckets {
+		if now.After(b.expires) {
+			delete(s.buckets, key)
+		}
+	}
+	for key, w := range s.windows {
+		if now.After(w.expires) {
+			delete(s.windows, key)
+		}
+	}
+}
@@ -0,0 +1,210 @@
+package ratelimit
+
+import (
+	"context"
+	"fmt"
+	"github.com/go-redis/redis/v8"
+	"github.com/spf13/viper"
+	"math"
+	"time"
+)
+
+// 限流算法
+const (
+	TokenBucket   = "token_bucket"
+	SlidingWindow = "sliding_window"
+)
+
+// 限流键的来源
+const (
+	KeyIP     = "ip"
+	KeyUser   = "user"
+	KeyAPIKey = "api_key"
+)
+
+// Policy 一个路由组的限流策略
+// 令牌桶每个 Period 补充 Limit 个令牌，最多积累 Burst 个；滑动窗口在任意 Period 内最多允许 Limit 次请求
+type Policy struct {
+	Name      string
+	Algorithm string
+	Limit     int
+	Period    time.Duration
+	Burst     int
+	Key       string
+}
+
+// Capacity 策略允许的最大瞬时请求数
+func (p Policy) Capacity() int {
+	if p.Algorithm == TokenBucket {
+		return p.Burst
+	}
+	return p.Limit
+}
+
+// Result 一次限流判断的结果
+type Result struct {
+	Allowed   bool
+	Limit     int
+	Remaining int
+	// Reset 配额完全恢复所需的时间
+	Reset time.Duration
+	// RetryAfter 被拒绝时距离下一次可用的时间
+	RetryAfter time.Duration
+}
+
+// Store 限流状态的存储，每次调用需原子地判断并消耗一次配额
+type Store interface {
+	TokenBucket(ctx context.Context, key string, capacity int, rate float64, now time.Time) (Result, error)
+	SlidingWindow(ctx context.Context, key string, limit int, window time.Duration, now time.Time) (Result, error)
+	// Ping 检查存储是否可用
+	Ping(ctx context.Context) error
+	Close() error
+}
+
+// Limiter 按策略名称限流
+type Limiter struct {
+	store    Store
+	policies map[string]Policy
+}
+
+var limiter *Limiter
+
+func NewLimiter(store Store, policies ...Policy) *Limiter {
+	l := &Limiter{store: store, policies: make(map[string]Policy)}
+	for _, policy := range policies {
+		l.policies[policy.Name] = policy
+	}
+	return l
+}
+
+// InitLimiter 按配置 ratelimit 创建限流器，ratelimit.store 为 memory 或 redis
+func InitLimiter() *Limiter {
+	var store Store
+	switch viper.GetString("ratelimit.store") {
+	case "", "memory":
+		store = NewMemoryStore()
+	case "redis":
+		store = NewRedisStore(redis.NewClient(&redis.Options{
+			Addr:     viper.GetString("ratelimit.redis.addr"),
+			Password: viper.GetString("ratelimit.redis.password"),
+			DB:       viper.GetInt("ratelimit.redis.db"),
+		}), viper.GetString("ratelimit.redis.prefix"))
+	default:
+		panic("unsupported ratelimit store: " + viper.GetString("ratelimit.store"))
+	}
+
+	var policies []Policy
+	for name := range viper.GetStringMap("ratelimit.policies") {
+		policy, err := loadPolicy(name)
+		if err != nil {
+			panic(err)
+		}
+		policies = append(policies, policy)
+	}
+
+	limiter = NewLimiter(store, policies...)
+	return limiter
+}
+
+func GetLimiter() *Limiter {
+	return limiter
+}
+
+func loadPolicy(name string) (Policy, error) {
+	key := "ratelimit.policies." + name
+	policy := Policy{
+		Name:      name,
+		Algorithm: viper.GetString(key + ".algorithm"),
+		Limit:     viper.GetInt(key + ".limit"),
+		Period:    viper.GetDuration(key + ".period"),
+		Burst:     viper.GetInt(key + ".burst"),
+		Key:       viper.GetString(key + ".key"),
+	}
+	if policy.Algorithm == "" {
+		policy.Algorithm = TokenBucket
+	}
+	if policy.Period <= 0 {
+		policy.Period = time.Minute
+	}
+	if policy.Burst <= 0 {
+		policy.Burst = policy.Limit
+	}
+	if policy.Key == "" {
+		policy.Key = KeyIP
+	}
+
+	if policy.Limit <= 0 {
+		return policy, fmt.Errorf("ratelimit policy %s: limit must be positive", name)
+	}
+	if policy.Algorithm != TokenBucket && policy.Algorithm != SlidingWindow {
+		return policy, fmt.Errorf("ratelimit policy %s: unsupported algorithm %s", name, policy.Algorithm)
+	}
+	if policy.Key != KeyIP && policy.Key != KeyUser && policy.Key != KeyAPIKey {
+		return policy, fmt.Errorf("ratelimit policy %s: unsupported key %s", name, policy.Key)
+	}
+	return policy, nil
+}
+
+// Policy 按名称获取策略
+func (l *Limiter) Policy(name string) (Policy, bool) {
+	policy, ok := l.policies[name]
+	return policy, ok
+}
+
+// Allow 对 key 消耗一次策略配额
+func (l *Limiter) Allow(ctx context.Context, policy Policy, key string) (Result, error) {
+	key = policy.Name + ":" + key
+	now := time.Now()
+	if policy.Algorithm == SlidingWindow {
+		return l.store.SlidingWindow(ctx, key, policy.Limit, policy.Period, now)
+	}
+	return l.store.TokenBucket(ctx, key, policy.Burst, float64(policy.Limit)/policy.Period.Seconds(), now)
+}
+
+// Ping 检查存储是否可用
+func (l *Limiter) Ping(ctx context.Context) error {
+	return l.store.Ping(ctx)
+}
+
+// Close 关闭存储连接
+func (l *Limiter) Close() error {
+	return l.store.Close()
+}
+
+// bucketResult 由桶内剩余令牌计算令牌桶结果，rate 为每秒补充的令牌数
+func bucketResult(allowed bool, tokens float64, capacity int, rate float64) Result {
+	result := Result{
+		Allowed:   allowed,
+		Limit:     capacity,
+		Remaining: int(math.Floor(tokens)),
+		Reset:     seconds((float64(capacity) - tokens) / rate),
+	}
+	if !allowed {
+		result.RetryAfter = seconds((1 - tokens) / rate)
+	}
+	return result
+}
+
+// windowResult 由窗口内请求数和最早一次请求时间计算滑动窗口结果
+func windowResult(allowed bool, count int, oldest time.Time, limit int, window time.Duration, now time.Time) Result {
+	result := Result{
+		Allowed:   allowed,
+		Limit:     limit,
+		Remaining: limit - count,
+		Reset:     oldest.Add(window).Sub(now),
+	}
+	if result.Remaining < 0 {
+		result.Remaining = 0
+	}
+	if !allowed {
+		result.RetryAfter = result.Reset
+	}
+	return result
+}
+
+func seconds(s float64) time.Duration {
+	if s <= 0 {
+		return 0
+	}
+	return time.Duration(s * float64(time.Second))
+}
@@ -0,0 +1,181 @@
+package ratelimit
+
+import (
+	"context"
+	"github.com/alicebob/miniredis/v2"
+	"github.com/go-redis/redis/v8"
+	"testing"
+	"time"
+)
+
+var base = time.Unix(1700000000, 0)
+
+// stores 内存存储和以 miniredis 作为本地替身的 Redis 存储，两者行为应一致
+var stores = map[string]func(t *testing.T) Store{
+	"memory": func(t *testing.T) Store {
+		return NewMemoryStore()
+	},
+	"redis": func(t *testing.T) Store {
+		server := miniredis.RunT(t)
+		client := redis.NewClient(&redis.Options{Addr: server.Addr()})
+		t.Cleanup(func() { client.Close() })
+		return NewRedisStore(client, "")
+	},
+}
+
+type step struct {
+	at         time.Duration
+	allowed    bool
+	remaining  int
+	reset      time.Duration
+	retryAfter time.Duration
+}
+
+func check(t *testing.T, i int, s step, result Result, limit int) {
+	t.Helper()
+	if result.Allowed != s.allowed {
+		t.Errorf("step %d: allowed = %v, want %v", i, result.Allowed, s.allowed)
+	}
+	if result.Limit != limit {
+		t.Errorf("step %d: limit = %d, want %d", i, result.Limit, limit)
+	}
+	if result.Remaining != s.remaining {
+		t.Errorf("step %d: remaining = %d, want %d", i, result.Remaining, s.remaining)
+	}
+	// Redis 中按毫秒和浮点数计算，允许 1ms 误差
+	if d := result.Reset - s.reset; d < -time.Millisecond || d > time.Millisecond {
+		t.Errorf("step %d: reset = %v, want %v", i, result.Reset, s.reset)
+	}
+	if d := result.RetryAfter - s.retryAfter; d < -time.Millisecond || d > time.Millisecond {
+		t.Errorf("step %d: retry after = %v, want %v", i, result.RetryAfter, s.retryAfter)
+	}
+}
+
+func TestTokenBucket(t *testing.T) {
+	// 容量 3，每秒补充 1 个令牌
+	steps := []step{
+		{at: 0, allowed: true, remaining: 2, reset: time.Second},
+		{at: 0, allowed: true, remaining: 1, reset: 2 * time.Second},
+		{at: 0, allowed: true, remaining: 0, reset: 3 * time.Second},
+		{at: 0, allowed: false, remaining: 0, reset: 3 * time.Second, retryAfter: time.Second},
+		{at: 500 * time.Millisecond, allowed: false, remaining: 0, reset: 2500 * time.Millisecond, retryAfter: 500 * time.Millisecond},
+		{at: time.Second, allowed: true, remaining: 0, reset: 3 * time.Second},
+		{at: 5 * time.Second, allowed: true, remaining: 2, reset: time.Second},
+	}
+
+	for name, newStore := range stores {
+		t.Run(name, func(t *testing.T) {
+			store := newStore(t)
+			for i, s := range steps {
+				result, err := store.TokenBucket(context.Background(), "k", 3, 1, base.Add(s.at))
+				if err != nil {
+					t.Fatal(err)
+				}
+				check(t, i, s, result, 3)
+			}
+		})
+	}
+}
+
+func TestSlidingWindow(t *testing.T) {
+	// 任意 1 秒内最多 2 次
+	steps := []step{
+		{at: 0, allowed: true, remaining: 1, reset: time.Second},
+		{at: 100 * time.Millisecond, allowed: true, remaining: 0, reset: 900 * time.Millisecond},
+		{at: 200 * time.Millisecond, allowed: false, remaining: 0, reset: 800 * time.Millisecond, retryAfter: 800 * time.Millisecond},
+		{at: time.Second, allowed: true, remaining: 0, reset: 100 * time.Millisecond},
+		{at: 1050 * time.Millisecond, allowed: false, remaining: 0, reset: 50 * time.Millisecond, retryAfter: 50 * time.Millisecond},
+		{at: 3 * time.Second, allowed: true, remaining: 1, reset: time.Second},
+	}
+
+	for name, newStore := range stores {
+		t.Run(name, func(t *testing.T) {
+			store := newStore(t)
+			for i, s := range steps {
+				result, err := store.SlidingWindow(context.Background(), "k", 2, time.Second, base.Add(s.at))
+				if err != nil {
+					t.Fatal(err)
+				}
+				check(t, i, s, result, 2)
+			}
+		})
+	}
+}
+
+func TestKeysAreIndependent(t *testing.T) {
+	for name, newStore := range stores {
+		t.Run(name, func(t *testing.T) {
+			store := newStore(t)
+			ctx := context.Background()
+			if result, _ := store.TokenBucket(ctx, "a", 1, 1, base); !result.Allowed {
+				t.Fatal("first request for a denied")
+			}
+			if result, _ := store.TokenBucket(ctx, "a", 1, 1, base); result.Allowed {
+				t.Fatal("second request for a allowed")
+			}
+			if result, _ := store.TokenBucket(ctx, "b", 1, 1, base); !result.Allowed {
+				t.Fatal("first request for b denied")
+			}
+		})
+	}
+}
+
+func TestRedisExpiry(t *testing.T) {
+	server := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: server.Addr()})
+	defer client.Close()
+	store := NewRedisStore(client, "rl:")
+	ctx := context.Background()
+
+	// 桶空后 3 秒补满，键在补满后过期
+	for i := 0; i < 3; i++ {
+		if _, err := store.TokenBucket(ctx, "bucket", 3, 1, base); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if ttl := server.TTL("rl:bucket"); ttl < 3*time.Second || ttl > 3*time.Second+10*time.Millisecond {
+		t.Errorf("bucket ttl = %v, want about 3s", ttl)
+	}
+
+	if _, err := store.SlidingWindow(ctx, "window", 2, time.Second, base); err != nil {
+		t.Fatal(err)
+	}
+	if ttl := server.TTL("rl:window"); ttl != time.Second {
+		t.Errorf("window ttl = %v, want 1s", ttl)
+	}
+
+	// 过期后状态重置
+	server.FastForward(4 * time.Second)
+	result, err := store.TokenBucket(ctx, "bucket", 3, 1, base)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !result.Allowed || result.Remaining != 2 {
+		t.Errorf("after expiry: allowed = %v, remaining = %d, want true, 2", result.Allowed, result.Remaining)
+	}
+}
+
+func TestLimiterPolicyRate(t *testing.T) {
+	for name, newStore := range stores {
+		t.Run(name, func(t *testing.T) {
+			store := newStore(t)
+			// 每分钟 60 次即每秒 1 次，最多积累 2 次
+			policy := Policy{Name: "p", Algorithm: TokenBucket, Limit: 60, Period: time.Minute, Burst: 2, Key: KeyIP}
+			limiter := NewLimiter(store, policy)
+			ctx := context.Background()
+
+			for i, want := range []bool{true, true, false} {
+				result, err := limiter.Allow(ctx, policy, "ip:1")
+				if err != nil {
+					t.Fatal(err)
+				}
+				if result.Allowed != want {
+					t.Fatalf("request %d: allowed = %v, want %v", i, result.Allowed, want)
+				}
+				if !want && (result.RetryAfter <= 0 || result.RetryAfter > time.Second) {
+					t.Errorf("retry after = %v, want within 1s", result.RetryAfter)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,111 @@
+package ratelimit
+
+import (
+	"context"
+	"fmt"
+	"github.com/go-redis/redis/v8"
+	"math/rand"
+	"strconv"
+	"time"
+)
+
+// tokenBucketScript 补充令牌并尝试取出一个，返回是否允许和剩余令牌数（字符串以保留小数）
+var tokenBucketScript = redis.NewScript(`
+local capacity = tonumber(ARGV[1])
+local rate = tonumber(ARGV[2])
+local now = tonumber(ARGV[3])
+local state = redis.call('HMGET', KEYS[1], 'tokens', 'updated')
+local tokens = tonumber(state[1]) or capacity
+local updated = tonumber(state[2]) or now
+if now > updated then
+	tokens = math.min(capacity, tokens + (now - updated) * rate)
+	updated = now
+end
+local allowed = 0
+if tokens >= 1 then
+	tokens = tokens - 1
+	allowed = 1
+end
+redis.call('HMSET', KEYS[1], 'tokens', tostring(tokens), 'updated', updated)
+redis.call('PEXPIRE', KEYS[1], math.ceil((capacity - tokens) / rate) + 1)
+return {allowed, tostring(tokens)}
+`)
+
+// slidingWindowScript 以有序集合记录窗口内每次请求，返回是否允许、窗口内请求数和最早一次请求时间
+var slidingWindowScript = redis.NewScript(`
+local limit = tonumber(ARGV[1])
+local window = tonumber(ARGV[2])
+local now = tonumber(ARGV[3])
+redis.call('ZREMRANGEBYSCORE', KEYS[1], '-inf', now - window)
+local count = redis.call('ZCARD', KEYS[1])
+local allowed = 0
+if count < limit then
+	redis.call('ZADD', KEYS[1], now, ARGV[4])
+	count = count + 1
+	allowed = 1
+end
+redis.call('PEXPIRE', KEYS[1], window)
+local oldest = redis.call('ZRANGE', KEYS[1], 0, 0, 'WITHSCORES')
+return {allowed, count, oldest[2] or ARGV[3]}
+`)
+
+// RedisStore 多实例共享的 Redis 存储，判断和消耗通过 Lua 脚本原子执行，时间以毫秒计
+type RedisStore struct {
+	client redis.UniversalClient
+	prefix string
+}
+
+// NewRedisStore client 可以是任意兼容 Redis 协议的服务，prefix 为键前缀，默认 ratelimit:
+func NewRedisStore(client redis.UniversalClient, prefix string) *RedisStore {
+	if prefix == "" {
+		prefix = "ratelimit:"
+	}
+	return &RedisStore{client: client, prefix: prefix}
+}
+
+func (s *RedisStore) TokenBucket(ctx context.Context, key string, capacity int, rate float64, now time.Time) (Result, error) {
+	values, err := tokenBucketScript.Run(ctx, s.client, []string{s.prefix + key},
+		capacity, strconv.FormatFloat(rate/1000, 'g', -1, 64), now.UnixNano()/int64(time.Millisecond)).Slice()
+	if err != nil {
+		return Result{}, err
+	}
+	if len(values) != 2 {
+		return Result{}, fmt.Errorf("unexpected token bucket script result: %v", values)
+	}
+
+	tokens, err := strconv.ParseFloat(fmt.Sprint(values[1]), 64)
+	if err != nil {
+		return Result{}, err
+	}
+	return bucketResult(values[0] == int64(1), tokens, capacity, rate), nil
+}
+
+func (s *RedisStore) SlidingWindow(ctx context.Context, key string, limit int, window time.Duration, now time.Time) (Result, error) {
+	nowMillis := now.UnixNano() / int64(time.Millisecond)
+	// 同一毫秒内的请求需要不同的成员
+	member := strconv.FormatInt(nowMillis, 10) + "-" + strconv.FormatInt(rand.Int63(), 36)
+	values, err := slidingWindowScript.Run(ctx, s.client, []string{s.prefix + key},
+		limit, window.Milliseconds(), nowMillis, member).Slice()
+	if err != nil {
+		return Result{}, err
+	}
+	if len(values) != 3 {
+		return Result{}, fmt.Errorf("unexpected sliding window script result: %v", values)
+	}
+
+	count, _ := values[1].(int64)
+	oldestMillis, err := strconv.ParseInt(fmt.Sprint(values[2]), 10, 64)
+	if err != nil {
+		return Result{}, err
+	}
+	oldest := time.Unix(0, oldestMillis*int64(time.Millisecond))
+	return windowResult(values[0] == int64(1), int(count), oldest, limit, window, now), nil
+}
+
+func (s *RedisStore) Ping(ctx context.Context) error {
+	return s.client.Ping(ctx).Err()
+}
+
+func (s *RedisStore) Close() error {
+	return s.client.Close()
+}
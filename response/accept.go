@@ -0,0 +1,99 @@
+package response
+
+import (
+	"strconv"
+	"strings"
+)
+
+// mediaRange Accept 请求头中的一项，Type 和 Subtype 可以为 *
+type mediaRange struct {
+	Type    string
+	Subtype string
+	Quality float64
+}
+
+// offer 服务端可提供的媒体类型，Quality 为服务端偏好（同 Apache 的 qs），与客户端的 q 值相乘后比较
+type offer struct {
+	MIME    string
+	Quality float64
+}
+
+// parseAccept 解析 Accept 请求头，忽略格式不正确的项和 q 值无效的项
+func parseAccept(header string) []mediaRange {
+	var ranges []mediaRange
+	for _, item := range strings.Split(header, ",") {
+		params := strings.Split(item, ";")
+		mime := strings.ToLower(strings.TrimSpace(params[0]))
+		slash := strings.IndexByte(mime, '/')
+		if slash <= 0 || slash == len(mime)-1 {
+			continue
+		}
+		r := mediaRange{Type: mime[:slash], Subtype: mime[slash+1:], Quality: 1}
+		if r.Type == "*" && r.Subtype != "*" {
+			continue
+		}
+
+		valid := true
+		for _, param := range params[1:] {
+			name, value := param, ""
+			if eq := strings.IndexByte(param, '='); eq >= 0 {
+				name, value = param[:eq], param[eq+1:]
+			}
+			if strings.ToLower(strings.TrimSpace(name)) != "q" {
+				continue
+			}
+			q, err := strconv.ParseFloat(strings.TrimSpace(value), 64)
+			if err != nil || q < 0 || q > 1 {
+				valid = false
+				break
+			}
+			r.Quality = q
+		}
+		if valid {
+			ranges = append(ranges, r)
+		}
+	}
+	return ranges
+}
+
+// acceptQuality 客户端对 mime 的 q 值，取最具体的匹配项（type/subtype 优先于 type/*，再优先于 */*），没有匹配项时为 0
+func acceptQuality(ranges []mediaRange, mime string) float64 {
+	slash := strings.IndexByte(mime, '/')
+	mimeType, mimeSubtype := mime[:slash], mime[slash+1:]
+
+	quality, specificity := 0.0, 0
+	for _, r := range ranges {
+		s := 0
+		switch {
+		case r.Type == mimeType && r.Subtype == mimeSubtype:
+			s = 3
+		case r.Type == mimeType && r.Subtype == "*":
+			s = 2
+		case r.Type == "*" && r.Subtype == "*":
+			s = 1
+		default:
+			continue
+		}
+		if s > specificity || (s == specificity && r.Quality > quality) {
+			quality, specificity = r.Quality, s
+		}
+	}
+	return quality
+}
+
+// negotiateMIME 按 Accept 请求头从 offers 中选出得分最高的媒体类型，得分相同时取靠前的；
+// 未声明 Accept 或其中没有可解析的项时返回第一个，都不可接受时返回空字符串
+func negotiateMIME(header string, offers []offer) string {
+	ranges := parseAccept(header)
+	if len(ranges) == 0 {
+		return offers[0].MIME
+	}
+
+	best, bestScore := "", 0.0
+	for _, o := range offers {
+		if score := acceptQuality(ranges, o.MIME) * o.Quality; score > bestScore {
+			best, bestScore = o.MIME, score
+		}
+	}
+	return best
+}
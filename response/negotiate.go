@@ -0,0 +1,197 @@
+package response
+
+import (
+	"bytes"
+	"encoding/json"
+	"encoding/xml"
+	"gin-swagger/errcode"
+	"gin-swagger/logger"
+	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
+	"github.com/gin-gonic/gin/render"
+	"net/http"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// 支持的响应格式，可通过 format 查询参数指定，优先于 Accept 请求头
+const (
+	FormatJSON    = "json"
+	FormatXML     = "xml"
+	FormatYAML    = "yaml"
+	FormatMsgPack = "msgpack"
+)
+
+// offeredMIMEs 参与 Accept 协商的媒体类型，第一个为未声明 Accept 时的默认值；
+// 其他格式的服务端偏好低于 JSON，浏览器的 application/xml;q=0.9,*/*;q=0.8 之类的请求头仍返回 JSON
+var offeredMIMEs = []offer{
+	{binding.MIMEJSON, 1},
+	{MIMEProblemJSON, 1},
+	{binding.MIMEXML, 0.8},
+	{binding.MIMEXML2, 0.8},
+	{binding.MIMEYAML, 0.8},
+	{binding.MIMEMSGPACK, 0.8},
+	{binding.MIMEMSGPACK2, 0.8},
+}
+
+var mimeFormats = map[string]string{
+	binding.MIMEJSON:     FormatJSON,
+	MIMEProblemJSON:      FormatJSON,
+	binding.MIMEXML:      FormatXML,
+	binding.MIMEXML2:     FormatXML,
+	binding.MIMEYAML:     FormatYAML,
+	binding.MIMEMSGPACK:  FormatMsgPack,
+	binding.MIMEMSGPACK2: FormatMsgPack,
+}
+
+// ignoreFormatQueryKey 上下文中为 true 时 format 查询参数不参与响应格式协商
+const ignoreFormatQueryKey = "response.ignore_format_query"
+
+// IgnoreFormatQuery 用于 format 查询参数另有含义的接口（如导出文件格式），该请求的响应格式只按 Accept 协商
+func IgnoreFormatQuery(ctx *gin.Context) {
+	ctx.Set(ignoreFormatQueryKey, true)
+}
+
+// negotiateFormat 选择响应格式，无法满足时返回 false
+func negotiateFormat(ctx *gin.Context) (string, bool) {
+	if format := ctx.Query("format"); format != "" && !ctx.GetBool(ignoreFormatQueryKey) {
+		switch format {
+		case FormatJSON, FormatXML, FormatYAML, FormatMsgPack:
+			return format, true
+		}
+		return format, false
+	}
+
+	mime := negotiateMIME(ctx.GetHeader("Accept"), offeredMIMEs)
+	if mime == "" {
+		return ctx.GetHeader("Accept"), false
+	}
+	return mimeFormats[mime], true
+}
+
+// respond 按协商出的格式写出响应体，非 JSON 格式先转换为与 JSON 相同的字段结构
+func respond(ctx *gin.Context, status int, obj interface{}) {
+	ctx.Writer.Header().Add("Vary", "Accept")
+	format, ok := negotiateFormat(ctx)
+	if !ok {
+		notAcceptable(ctx, format)
+		return
+	}
+	if format == FormatJSON {
+		ctx.JSON(status, obj)
+		return
+	}
+
+	value, err := normalize(obj)
+	if err != nil {
+		logger.FromContext(ctx).Error("normalize response failed", "format", format, "error", err)
+		ctx.JSON(http.StatusInternalServerError, errorEnvelope(ctx, errcode.New(errcode.Internal), nil))
+		return
+	}
+	switch format {
+	case FormatXML:
+		var buf bytes.Buffer
+		buf.WriteString(xml.Header)
+		if err := encodeXML(xml.NewEncoder(&buf), "response", value); err != nil {
+			logger.FromContext(ctx).Error("encode xml response failed", "error", err)
+		}
+		ctx.Data(status, binding.MIMEXML+"; charset=utf-8", buf.Bytes())
+	case FormatYAML:
+		ctx.Render(status, render.YAML{Data: value})
+	case FormatMsgPack:
+		ctx.Render(status, render.MsgPack{Data: value})
+	}
+}
+
+// notAcceptable 请求的格式都不支持时以 JSON 返回 406
+func notAcceptable(ctx *gin.Context, format string) {
+	e := errcode.New(errcode.NotAcceptable, format)
+	ctx.JSON(e.Status(), errorEnvelope(ctx, e, gin.H{"formats": []string{FormatJSON, FormatXML, FormatYAML, FormatMsgPack}}))
+}
+
+// normalize 经 JSON 编解码得到通用结构，使各格式的字段名与 JSON 一致，整数保持为整数
+func normalize(obj interface{}) (interface{}, error) {
+	data, err := json.Marshal(obj)
+	if err != nil {
+		return nil, err
+	}
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	var value interface{}
+	if err := decoder.Decode(&value); err != nil {
+		return nil, err
+	}
+	return convertNumbers(value), nil
+}
+
+func convertNumbers(value interface{}) interface{} {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		for key, item := range v {
+			v[key] = convertNumbers(item)
+		}
+	case []interface{}:
+		for i, item := range v {
+			v[i] = convertNumbers(item)
+		}
+	case json.Number:
+		if n, err := v.Int64(); err == nil {
+			return n
+		}
+		f, _ := v.Float64()
+		return f
+	}
+	return value
+}
+
+var xmlNamePattern = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_.-]*$`)
+
+// encodeXML 对象的键转为子元素，数组元素为 item，键不是合法元素名时使用带 key 属性的 entry 元素
+func encodeXML(encoder *xml.Encoder, name string, value interface{}) error {
+	start := xml.StartElement{Name: xml.Name{Local: name}}
+	if !xmlNamePattern.MatchString(name) || strings.HasPrefix(strings.ToLower(name), "xml") {
+		start = xml.StartElement{Name: xml.Name{Local: "entry"}, Attr: []xml.Attr{{Name: xml.Name{Local: "key"}, Value: name}}}
+	}
+	if err := encoder.EncodeToken(start); err != nil {
+		return err
+	}
+
+	switch v := value.(type) {
+	case map[string]interface{}:
+		keys := make([]string, 0, len(v))
+		for key := range v {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		for _, key := range keys {
+			if err := encodeXML(encoder, key, v[key]); err != nil {
+				return err
+			}
+		}
+	case []interface{}:
+		for _, item := range v {
+			if err := encodeXML(encoder, "item", item); err != nil {
+				return err
+			}
+		}
+	case nil:
+	default:
+		if err := encoder.EncodeToken(xml.CharData(toText(v))); err != nil {
+			return err
+		}
+	}
+
+	if err := encoder.EncodeToken(start.End()); err != nil {
+		return err
+	}
+	return encoder.Flush()
+}
+
+func toText(value interface{}) string {
+	if s, ok := value.(string); ok {
+		return s
+	}
+	data, _ := json.Marshal(value)
+	return string(data)
+}
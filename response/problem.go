@@ -0,0 +1,50 @@
+package response
+
+import (
+	"gin-swagger/errcode"
+	"gin-swagger/logger"
+	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
+	"github.com/spf13/viper"
+	"net/http"
+)
+
+// MIMEProblemJSON RFC 7807 错误文档的媒体类型
+const MIMEProblemJSON = "application/problem+json"
+
+// problemOffers application/json 在前，q 值相同时仍使用原有响应格式
+var problemOffers = []offer{{binding.MIMEJSON, 1}, {MIMEProblemJSON, 1}}
+
+// wantsProblem 客户端是否要求以 RFC 7807 文档返回错误，未声明时仍使用原有响应格式
+func wantsProblem(ctx *gin.Context) bool {
+	return negotiateMIME(ctx.GetHeader("Accept"), problemOffers) == MIMEProblemJSON
+}
+
+// problem 以 RFC 7807 文档渲染错误，错误码、字段错误、附加数据和请求ID作为扩展成员
+func problem(ctx *gin.Context, e *errcode.Error, status int, lang string) {
+	// 未配置 problem.type_base 时按规范使用 about:blank，title 取 HTTP 状态描述
+	problemType, title := "about:blank", http.StatusText(status)
+	if base := viper.GetString("problem.type_base"); base != "" {
+		problemType, title = base+string(e.Code), e.Message(lang)
+	}
+
+	doc := gin.H{}
+	for k, v := range e.Data {
+		doc[k] = v
+	}
+	if fields := e.FieldErrors(lang); len(fields) > 0 {
+		doc["errors"] = fields
+	}
+	if id := logger.RequestID(ctx); id != "" {
+		doc["request_id"] = id
+	}
+	doc["type"] = problemType
+	doc["title"] = title
+	doc["status"] = status
+	doc["detail"] = e.Message(lang)
+	doc["instance"] = ctx.Request.URL.RequestURI()
+	doc["code"] = e.Code
+
+	ctx.Header("Content-Type", MIMEProblemJSON)
+	ctx.JSON(status, doc)
+}
@@ -1,18 +1,52 @@
 package response
 
 import (
+	"gin-swagger/errcode"
+	"gin-swagger/i18n"
+	"gin-swagger/logger"
 	"github.com/gin-gonic/gin"
 	"net/http"
 )
 
+// Response 按 Accept 请求头或 format 查询参数协商格式渲染响应，支持 JSON、XML、YAML 和 MessagePack，都不支持时返回 406
 func Response(ctx *gin.Context, httpStatus int, code int, data gin.H, msg string)  {
-	ctx.JSON(httpStatus,gin.H{"code": code, "data": data, "msg": msg})
+	respond(ctx, httpStatus, gin.H{"code": code, "data": data, "msg": msg})
 }
 
+// Success 渲染成功响应，msg 为 i18n 消息ID，按请求语言翻译
 func Success(ctx *gin.Context, data gin.H, msg string)  {
-	Response(ctx, http.StatusOK, 200, data, msg)
+	Response(ctx, http.StatusOK, 200, data, i18n.T(i18n.FromContext(ctx), msg))
 }
 
-func Fail(ctx *gin.Context, data gin.H, msg string)  {
-	Response(ctx, http.StatusOK, 400, data, msg)
-}
\ No newline at end of file
+// Error 渲染业务错误，code 与 HTTP 状态码一致，error 为错误码，字段校验错误放在 data.errors；
+// 客户端 Accept 为 application/problem+json 时改为渲染 RFC 7807 文档
+func Error(ctx *gin.Context, err error) {
+	e := errcode.From(err)
+	status := e.Status()
+	if status >= http.StatusInternalServerError {
+		logger.FromContext(ctx).Error("request failed", "method", ctx.Request.Method, "path", ctx.Request.URL.Path, "error", err)
+	}
+	lang := i18n.FromContext(ctx)
+	if wantsProblem(ctx) {
+		problem(ctx, e, status, lang)
+		return
+	}
+
+	data := e.Data
+	if fields := e.FieldErrors(lang); len(fields) > 0 {
+		data = gin.H{"errors": fields}
+		for k, v := range e.Data {
+			data[k] = v
+		}
+	}
+	respond(ctx, status, errorEnvelope(ctx, e, data))
+}
+
+// errorEnvelope 错误响应体，在原有格式上增加错误码 error 和请求ID request_id
+func errorEnvelope(ctx *gin.Context, e *errcode.Error, data gin.H) gin.H {
+	envelope := gin.H{"code": e.Status(), "error": e.Code, "data": data, "msg": e.Message(i18n.FromContext(ctx))}
+	if requestID := logger.RequestID(ctx); requestID != "" {
+		envelope["request_id"] = requestID
+	}
+	return envelope
+}
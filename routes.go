@@ -2,14 +2,27 @@ package main
 
 import (
 	"gin-swagger/controller"
+	"gin-swagger/metrics"
 	"gin-swagger/middleware"
+	"gin-swagger/tracing"
 	"github.com/gin-gonic/gin"
 	swaggerfiles "github.com/swaggo/files"
 	ginSwagger "github.com/swaggo/gin-swagger"
+	"go.opentelemetry.io/contrib/instrumentation/github.com/gin-gonic/gin/otelgin"
 )
 
 func CollectRoute(r *gin.Engine) *gin.Engine {
+	r.Use(otelgin.Middleware(tracing.ServiceName(), otelgin.WithPropagators(tracing.Propagator())))
+	r.Use(middleware.RequestIDMiddleware())
+	r.Use(middleware.TracingMiddleware())
+	r.Use(middleware.ClientIdentityMiddleware())
+	r.Use(middleware.AccessLogMiddleware())
+	r.Use(middleware.MetricsMiddleware())
+	// 处理函数的 panic 在访问日志和指标之内恢复，使其仍能记录 500
+	r.Use(middleware.RecoveryMiddleware())
 	r.Use(middleware.Cors())
+	r.Use(middleware.LocaleMiddleware())
+	r.Use(middleware.ErrorMiddleware())
 	v1 := r.Group("/api/v1")
 	{
 		eg := v1.Group("/example")
@@ -17,14 +30,14 @@ func CollectRoute(r *gin.Engine) *gin.Engine {
 			eg.GET("/helloworld",controller.Helloworld)
 		}
 	}
-	r.POST("/api/auth/register", controller.Register)
-	r.POST("/api/auth/login", controller.Login)
+	r.POST("/api/auth/register", middleware.RateLimitMiddleware("auth"), controller.Register)
+	r.POST("/api/auth/login", middleware.RateLimitMiddleware("auth"), controller.Login)
 	r.GET("/api/auth/info", middleware.AuthMiddleware() , controller.Info)
 
 	categoryRoutes := r.Group("/categories")
 	{
 		categoryController := controller.NewCategoryController()
-		categoryRoutes.POST("",categoryController.Create)
+		categoryRoutes.POST("", middleware.IdempotencyMiddleware(), categoryController.Create)
 		categoryRoutes.PUT("/:id", categoryController.Update)
 		categoryRoutes.GET("/:id", categoryController.Show)
 		categoryRoutes.DELETE("/:id", categoryController.Delete)
@@ -33,14 +46,71 @@ func CollectRoute(r *gin.Engine) *gin.Engine {
 	postRoutes := r.Group("/posts")
 	{
 		postRoutes.Use(middleware.AuthMiddleware())
+		postRoutes.Use(middleware.RateLimitMiddleware("posts"))
 		postController := controller.NewPostController()
-		postRoutes.POST("",postController.Create)
+		postRoutes.POST("", middleware.IdempotencyMiddleware(), postController.Create)
+		postRoutes.POST("/bulk", controller.NewBulkController().Posts)
+		postRoutes.GET("/search", postController.Search)
 		postRoutes.PUT("/:id", postController.Update)
 		postRoutes.GET("/:id", postController.Show)
 		postRoutes.DELETE("/:id", postController.Delete)
 		postRoutes.DELETE("page/list", postController.PageList)
+		postRoutes.PUT("/:id/comments/setting", postController.CommentSetting)
+
+		commentController := controller.NewCommentController()
+		postRoutes.GET("/:id/comments", commentController.PageList)
+		postRoutes.POST("/:id/comments", commentController.Create)
+
+		engagementController := controller.NewEngagementController()
+		postRoutes.PUT("/:id/like", engagementController.Like)
+		postRoutes.DELETE("/:id/like", engagementController.Unlike)
+		postRoutes.PUT("/:id/bookmark", engagementController.Bookmark)
+		postRoutes.DELETE("/:id/bookmark", engagementController.Unbookmark)
+		postRoutes.GET("/bookmarks", engagementController.Bookmarks)
+
+		trashController := controller.NewTrashController()
+		postRoutes.GET("/trash", trashController.PageList)
+		postRoutes.PUT("/:id/restore", trashController.Restore)
+		postRoutes.DELETE("/:id/permanent", trashController.Purge)
+
+		transferController := controller.NewTransferController()
+		postRoutes.GET("/export", transferController.Export)
+		postRoutes.POST("/import", transferController.Import)
+	}
+
+	commentRoutes := r.Group("/comments")
+	{
+		commentRoutes.Use(middleware.AuthMiddleware())
+		commentController := controller.NewCommentController()
+		commentRoutes.PUT("/:id", commentController.Update)
+		commentRoutes.GET("/:id", commentController.Show)
+		commentRoutes.DELETE("/:id", commentController.Delete)
 	}
 
+	adminRoutes := r.Group("/admin")
+	{
+		adminRoutes.Use(middleware.AuthMiddleware(), middleware.AdminMiddleware())
+		commentController := controller.NewCommentController()
+		adminRoutes.GET("/comments", commentController.ModerationList)
+		adminRoutes.PUT("/comments/:id/status", commentController.Moderate)
+	}
+
+	feedRoutes := r.Group("/feeds")
+	{
+		feedController := controller.NewFeedController()
+		feedRoutes.GET("/:format", feedController.Site)
+		feedRoutes.GET("/categories/:id/:format", feedController.Category)
+		feedRoutes.GET("/users/:id/:format", feedController.Author)
+	}
+
+	sitemapController := controller.NewSitemapController()
+	r.GET("/sitemap.xml", sitemapController.Sitemap)
+	r.GET("/sitemap.xml.gz", sitemapController.Sitemap)
+	r.GET("/sitemaps/:file", sitemapController.Part)
+
+	r.GET("/metrics", gin.WrapH(metrics.Handler()))
+	r.GET("/healthz", controller.Healthz)
+	r.GET("/readyz", controller.Readyz)
 	r.GET("/swagger/*any", ginSwagger.WrapHandler(swaggerfiles.Handler))
 	return r
 }
@@ -0,0 +1,181 @@
+package search
+
+import (
+	"gin-swagger/markdown"
+	"gin-swagger/model"
+	"github.com/blevesearch/bleve"
+	keywordAnalyzer "github.com/blevesearch/bleve/analysis/analyzer/keyword"
+	"github.com/blevesearch/bleve/analysis/lang/cjk"
+	"github.com/blevesearch/bleve/mapping"
+	"github.com/blevesearch/bleve/search/highlight/highlighter/html"
+	"os"
+	"sync"
+)
+
+const postDocType = "post"
+
+// postDocument 写入索引的文章文档
+type postDocument struct {
+	Title      string `json:"title"`
+	Content    string `json:"content"`
+	CategoryID uint   `json:"category_id"`
+	UserID     uint   `json:"user_id"`
+	Status     string `json:"status"`
+}
+
+func (postDocument) Type() string {
+	return postDocType
+}
+
+// BleveSearcher 基于 Bleve 的本地磁盘索引实现，使用 CJK 分词支持中文检索
+type BleveSearcher struct {
+	path  string
+	mu    sync.RWMutex
+	index bleve.Index
+}
+
+func NewBleveSearcher(path string) (*BleveSearcher, error) {
+	index, err := bleve.Open(path)
+	if err == bleve.ErrorIndexPathDoesNotExist {
+		index, err = bleve.New(path, newIndexMapping())
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return &BleveSearcher{path: path, index: index}, nil
+}
+
+func newIndexMapping() mapping.IndexMapping {
+	textField := bleve.NewTextFieldMapping()
+	textField.Analyzer = cjk.AnalyzerName
+	textField.Store = true
+	textField.IncludeTermVectors = true
+
+	numericField := bleve.NewNumericFieldMapping()
+	numericField.Store = false
+
+	keywordField := bleve.NewTextFieldMapping()
+	keywordField.Analyzer = keywordAnalyzer.Name
+	keywordField.Store = false
+
+	postMapping := bleve.NewDocumentMapping()
+	postMapping.AddFieldMappingsAt("title", textField)
+	postMapping.AddFieldMappingsAt("content", textField)
+	postMapping.AddFieldMappingsAt("category_id", numericField)
+	postMapping.AddFieldMappingsAt("user_id", numericField)
+	postMapping.AddFieldMappingsAt("status", keywordField)
+
+	indexMapping := bleve.NewIndexMapping()
+	indexMapping.DefaultAnalyzer = cjk.AnalyzerName
+	indexMapping.AddDocumentMapping(postDocType, postMapping)
+	return indexMapping
+}
+
+func (s *BleveSearcher) Index(posts ...model.Post) error {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	batch := s.index.NewBatch()
+	for _, post := range posts {
+		err := batch.Index(post.ID.String(), postDocument{
+			Title:      post.Title,
+			Content:    markdown.PlainText(post.Content),
+			CategoryID: post.CategoryID,
+			UserID:     post.UserID,
+			Status:     post.Status,
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return s.index.Batch(batch)
+}
+
+func (s *BleveSearcher) Delete(ids ...string) error {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	batch := s.index.NewBatch()
+	for _, id := range ids {
+		batch.Delete(id)
+	}
+	return s.index.Batch(batch)
+}
+
+func (s *BleveSearcher) Search(keyword string, viewerID uint, from int, size int) (*Result, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	// 标题命中权重高于正文
+	titleQuery := bleve.NewMatchQuery(keyword)
+	titleQuery.SetField("title")
+	titleQuery.SetBoost(2)
+	contentQuery := bleve.NewMatchQuery(keyword)
+	contentQuery.SetField("content")
+
+	// 只返回已发布或 viewerID 自己的文章，在索引中过滤以保证分页和总数准确
+	publishedQuery := bleve.NewTermQuery(model.PostStatusPublished)
+	publishedQuery.SetField("status")
+	owner, inclusive := float64(viewerID), true
+	ownerQuery := bleve.NewNumericRangeInclusiveQuery(&owner, &owner, &inclusive, &inclusive)
+	ownerQuery.SetField("user_id")
+	visibleQuery := bleve.NewDisjunctionQuery(publishedQuery, ownerQuery)
+
+	query := bleve.NewConjunctionQuery(bleve.NewDisjunctionQuery(titleQuery, contentQuery), visibleQuery)
+	request := bleve.NewSearchRequestOptions(query, size, from, false)
+	request.Highlight = bleve.NewHighlightWithStyle(html.Name)
+	request.Highlight.AddField("title")
+	request.Highlight.AddField("content")
+
+	searchResult, err := s.index.Search(request)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &Result{Total: searchResult.Total, Hits: make([]Hit, 0, len(searchResult.Hits))}
+	for _, hit := range searchResult.Hits {
+		result.Hits = append(result.Hits, Hit{
+			ID:         hit.ID,
+			Score:      hit.Score,
+			Highlights: hit.Fragments,
+		})
+	}
+	return result, nil
+}
+
+// Clear 删除索引目录后重新创建空索引
+func (s *BleveSearcher) Clear() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if err := s.index.Close(); err != nil {
+		return err
+	}
+	if err := os.RemoveAll(s.path); err != nil {
+		return err
+	}
+	index, err := bleve.New(s.path, newIndexMapping())
+	if err != nil {
+		return err
+	}
+
+	s.index = index
+	return nil
+}
+
+// Ping 读取文档数，索引已关闭或损坏时返回错误
+func (s *BleveSearcher) Ping() error {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	_, err := s.index.DocCount()
+	return err
+}
+
+func (s *BleveSearcher) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.index.Close()
+}
@@ -0,0 +1,76 @@
+package search
+
+import (
+	"gin-swagger/model"
+	"github.com/spf13/viper"
+	"gorm.io/gorm"
+)
+
+// Searcher 文章全文检索接口
+type Searcher interface {
+	// Index 新增或覆盖文章索引
+	Index(posts ...model.Post) error
+	// Delete 按文章ID删除索引
+	Delete(ids ...string) error
+	// Search 按关键字检索 viewerID 可见的文章（已发布或本人的草稿），from/size 为分页偏移和条数
+	Search(keyword string, viewerID uint, from int, size int) (*Result, error)
+	// Clear 清空全部索引
+	Clear() error
+	// Ping 检查索引是否可用
+	Ping() error
+	Close() error
+}
+
+// Hit 单条检索结果
+type Hit struct {
+	ID         string              `json:"id"`
+	Score      float64             `json:"score"`
+	Highlights map[string][]string `json:"highlights"`
+}
+
+// Result 检索结果
+type Result struct {
+	Total uint64 `json:"total"`
+	Hits  []Hit  `json:"hits"`
+}
+
+const rebuildBatchSize = 500
+
+var searcher Searcher
+
+func InitSearcher() Searcher {
+	path := viper.GetString("search.path")
+	if path == "" {
+		path = "data/search.bleve"
+	}
+	s, err := NewBleveSearcher(path)
+	if err != nil {
+		panic("failed to open search index, err: " + err.Error())
+	}
+
+	searcher = s
+	return s
+}
+
+func GetSearcher() Searcher {
+	return searcher
+}
+
+// Rebuild 清空索引并从数据库重新导入全部文章，返回导入条数
+func Rebuild(s Searcher, db *gorm.DB) (int, error) {
+	if err := s.Clear(); err != nil {
+		return 0, err
+	}
+
+	total := 0
+	var posts []model.Post
+	err := db.Model(model.Post{}).FindInBatches(&posts, rebuildBatchSize, func(tx *gorm.DB, batch int) error {
+		if err := s.Index(posts...); err != nil {
+			return err
+		}
+		total += len(posts)
+		return nil
+	}).Error
+
+	return total, err
+}
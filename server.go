@@ -0,0 +1,223 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"gin-swagger/counter"
+	"gin-swagger/dao"
+	"gin-swagger/health"
+	"gin-swagger/idempotency"
+	"gin-swagger/logger"
+	"gin-swagger/ratelimit"
+	"gin-swagger/search"
+	"gin-swagger/tlsconfig"
+	"gin-swagger/tracing"
+	"gin-swagger/trash"
+	"github.com/spf13/viper"
+	"golang.org/x/net/http2"
+	"golang.org/x/net/http2/h2c"
+	"net"
+	"net/http"
+	"os"
+	"os/signal"
+	"strconv"
+	"syscall"
+	"time"
+)
+
+const defaultShutdownTimeout = 30 * time.Second
+
+// NewServer 按配置 server 创建 HTTP 服务，未配置的超时为 0 表示不限制；
+// 启用 server.tls 时同时启用 HTTP/2，未启用 TLS 时可通过 server.h2c 启用明文 HTTP/2
+func NewServer(handler http.Handler) *http.Server {
+	port := viper.GetString("server.port")
+	if port == "" {
+		port = "8080"
+	}
+	tlsConfig, err := tlsconfig.InitTLS()
+	if err != nil {
+		panic(err)
+	}
+	if tlsConfig == nil && viper.GetBool("server.h2c") {
+		handler = h2c.NewHandler(handler, &http2.Server{})
+	}
+
+	server := &http.Server{
+		Addr:              viper.GetString("server.host") + ":" + port,
+		Handler:           handler,
+		TLSConfig:         tlsConfig,
+		ReadTimeout:       viper.GetDuration("server.read_timeout"),
+		ReadHeaderTimeout: viper.GetDuration("server.read_header_timeout"),
+		WriteTimeout:      viper.GetDuration("server.write_timeout"),
+		IdleTimeout:       viper.GetDuration("server.idle_timeout"),
+		MaxHeaderBytes:    viper.GetInt("server.max_header_bytes"),
+	}
+	if tlsConfig != nil {
+		if err := http2.ConfigureServer(server, &http2.Server{}); err != nil {
+			panic(err)
+		}
+		tlsconfig.GetReloader().Start()
+	}
+	return server
+}
+
+// Listen 配置了 server.unix_socket 时监听 Unix 域套接字，否则监听 TCP 地址 server.Addr
+func Listen(server *http.Server) (net.Listener, error) {
+	path := viper.GetString("server.unix_socket")
+	if path == "" {
+		return net.Listen("tcp", server.Addr)
+	}
+
+	// 清理上次异常退出遗留的套接字文件，不删除其他类型的文件
+	if info, err := os.Lstat(path); err == nil && info.Mode()&os.ModeSocket != 0 {
+		if err := os.Remove(path); err != nil {
+			return nil, err
+		}
+	}
+	listener, err := net.Listen("unix", path)
+	if err != nil {
+		return nil, err
+	}
+	if mode := viper.GetString("server.unix_socket_mode"); mode != "" {
+		perm, err := strconv.ParseUint(mode, 8, 32)
+		if err == nil {
+			err = os.Chmod(path, os.FileMode(perm))
+		}
+		if err != nil {
+			listener.Close()
+			return nil, fmt.Errorf("chmod unix socket: %w", err)
+		}
+	}
+	return listener, nil
+}
+
+// Serve 启动服务并等待 SIGINT/SIGTERM，收到信号后优雅关闭，返回进程退出状态
+func Serve(server *http.Server) int {
+	log := logger.GetLogger()
+	listener, err := Listen(server)
+	if err != nil {
+		log.Error("listen failed", "error", err)
+		Cleanup()
+		return 1
+	}
+
+	errs := make(chan error, 1)
+	go func() {
+		log.Info("server started", "addr", listener.Addr().String(), "network", listener.Addr().Network(), "tls", server.TLSConfig != nil)
+		if server.TLSConfig != nil {
+			// 证书由 TLSConfig.GetCertificate 提供
+			errs <- server.ServeTLS(listener, "", "")
+		} else {
+			errs <- server.Serve(listener)
+		}
+	}()
+
+	quit := make(chan os.Signal, 1)
+	signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM)
+	defer signal.Stop(quit)
+
+	select {
+	case err := <-errs:
+		log.Error("server failed", "error", err)
+		Cleanup()
+		return 1
+	case sig := <-quit:
+		log.Info("shutting down", "signal", sig.String())
+	}
+
+	status := 0
+	if err := Shutdown(server); err != nil {
+		log.Error("shutdown failed", "error", err)
+		status = 1
+	}
+	if err := Cleanup(); err != nil {
+		status = 1
+	}
+	log.Info("server stopped")
+	return status
+}
+
+// Shutdown 先标记为未就绪并等待 server.shutdown_delay 让负载均衡摘除本实例，
+// 再停止接收新连接并在 server.shutdown_timeout 内等待处理中的请求结束，超时后强制关闭连接
+func Shutdown(server *http.Server) error {
+	health.SetShuttingDown()
+	if delay := viper.GetDuration("server.shutdown_delay"); delay > 0 {
+		time.Sleep(delay)
+	}
+
+	timeout := viper.GetDuration("server.shutdown_timeout")
+	if timeout <= 0 {
+		timeout = defaultShutdownTimeout
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	if err := server.Shutdown(ctx); err != nil {
+		server.Close()
+		return err
+	}
+	return nil
+}
+
+// Cleanup 按依赖顺序停止后台任务并释放资源：先停止会写库的任务，最后关闭数据库连接池
+func Cleanup() error {
+	log := logger.GetLogger()
+	steps := []struct {
+		name string
+		run  func() error
+	}{
+		{"tls certificate reloader", func() error {
+			if r := tlsconfig.GetReloader(); r != nil {
+				r.Stop()
+			}
+			return nil
+		}},
+		{"view counter", func() error {
+			if c := counter.GetViewCounter(); c != nil {
+				c.Stop()
+			}
+			return nil
+		}},
+		{"trash purger", func() error {
+			if p := trash.GetPurger(); p != nil {
+				p.Stop()
+			}
+			return nil
+		}},
+		{"search index", func() error {
+			if s := search.GetSearcher(); s != nil {
+				return s.Close()
+			}
+			return nil
+		}},
+		{"rate limiter", func() error {
+			if l := ratelimit.GetLimiter(); l != nil {
+				return l.Close()
+			}
+			return nil
+		}},
+		{"idempotency store", func() error {
+			if s := idempotency.GetStore(); s != nil {
+				return s.Close()
+			}
+			return nil
+		}},
+		{"tracer", func() error {
+			ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+			defer cancel()
+			return tracing.Shutdown(ctx)
+		}},
+		{"database", dao.CloseDB},
+	}
+
+	var firstErr error
+	for _, step := range steps {
+		if err := step.run(); err != nil {
+			log.Error("cleanup failed", "step", step.name, "error", err)
+			if firstErr == nil {
+				firstErr = err
+			}
+		}
+	}
+	return firstErr
+}
@@ -0,0 +1,312 @@
+package sitemap
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/xml"
+	"fmt"
+	"gin-swagger/model"
+	"gin-swagger/util"
+	"github.com/spf13/viper"
+	"gorm.io/gorm"
+	"sort"
+	"sync"
+	"time"
+)
+
+// MaxURLs 单个站点地图文件允许的最大 URL 数
+const MaxURLs = 50000
+
+const (
+	xmlns        = "http://www.sitemaps.org/schemas/sitemap/0.9"
+	lastModFmt   = "2006-01-02T15:04:05Z07:00"
+	refreshBatch = 1000
+)
+
+type urlEntry struct {
+	Loc     string `xml:"loc"`
+	LastMod string `xml:"lastmod,omitempty"`
+}
+
+type urlSet struct {
+	XMLName xml.Name   `xml:"urlset"`
+	Xmlns   string     `xml:"xmlns,attr"`
+	URLs    []urlEntry `xml:"url"`
+}
+
+type sitemapEntry struct {
+	Loc     string `xml:"loc"`
+	LastMod string `xml:"lastmod,omitempty"`
+}
+
+type sitemapIndex struct {
+	XMLName  xml.Name       `xml:"sitemapindex"`
+	Xmlns    string         `xml:"xmlns,attr"`
+	Sitemaps []sitemapEntry `xml:"sitemap"`
+}
+
+type entry struct {
+	loc     string
+	lastMod time.Time
+}
+
+// Generator 站点地图生成器
+// 首次刷新全量加载，之后按 updated_at 水位线只查询变化的文章和分类，渲染结果缓存到下次变化
+type Generator struct {
+	db       *gorm.DB
+	interval time.Duration
+
+	mu          sync.Mutex
+	entries     map[string]entry
+	watermark   time.Time
+	deletedMark time.Time
+	lastRefresh time.Time
+	dirty       bool
+	chunks      [][]byte
+	gzipped     map[int][]byte
+	chunkMods   []time.Time
+}
+
+var generator *Generator
+
+func NewGenerator(db *gorm.DB, interval time.Duration) *Generator {
+	return &Generator{
+		db:       db,
+		interval: interval,
+		entries:  make(map[string]entry),
+		dirty:    true,
+	}
+}
+
+func InitGenerator(db *gorm.DB) *Generator {
+	interval := viper.GetDuration("sitemap.refresh_interval")
+	if interval <= 0 {
+		interval = 5 * time.Minute
+	}
+
+	generator = NewGenerator(db, interval)
+	return generator
+}
+
+func GetGenerator() *Generator {
+	return generator
+}
+
+// Gzip 是否输出 gzip 压缩的分片文件
+func Gzip() bool {
+	return viper.GetBool("sitemap.gzip")
+}
+
+// Remove 移除已删除的文章，文章被硬删除后无法通过增量查询发现
+func (g *Generator) Remove(postIDs ...string) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	for _, id := range postIDs {
+		if _, ok := g.entries[postKey(id)]; ok {
+			delete(g.entries, postKey(id))
+			g.dirty = true
+		}
+	}
+}
+
+// RemoveCategory 移除已删除的分类
+func (g *Generator) RemoveCategory(categoryID uint) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	key := categoryKey(categoryID)
+	if _, ok := g.entries[key]; ok {
+		delete(g.entries, key)
+		g.dirty = true
+	}
+}
+
+// Refresh 距上次刷新超过间隔时，增量同步水位线之后变化的数据
+func (g *Generator) Refresh() error {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	if time.Since(g.lastRefresh) < g.interval {
+		return nil
+	}
+	// 水位线本身的记录会被重复处理，保证同一时刻写入的记录不会遗漏
+	watermark := g.watermark
+
+	var categories []model.Category
+	if err := g.db.Where("updated_at >= ?", watermark).Find(&categories).Error; err != nil {
+		return err
+	}
+	for _, category := range categories {
+		g.put(categoryKey(category.ID), util.SiteURL(fmt.Sprintf("/categories/%d", category.ID)), time.Time(category.UpdatedAt))
+	}
+
+	// 软删除不会更新 updated_at，按删除时间单独同步移入回收站的文章
+	var deleted []model.Post
+	err := g.db.Unscoped().Select("id", "deleted_at").Where("deleted_at >= ?", g.deletedMark).Find(&deleted).Error
+	if err != nil {
+		return err
+	}
+	for _, post := range deleted {
+		if _, ok := g.entries[postKey(post.ID.String())]; ok {
+			delete(g.entries, postKey(post.ID.String()))
+			g.dirty = true
+		}
+		if post.DeletedAt.Time.After(g.deletedMark) {
+			g.deletedMark = post.DeletedAt.Time
+		}
+	}
+
+	var posts []model.Post
+	err = g.db.Select("id", "status", "updated_at").Where("updated_at >= ?", watermark).
+		FindInBatches(&posts, refreshBatch, func(tx *gorm.DB, batch int) error {
+			for _, post := range posts {
+				key := postKey(post.ID.String())
+				if post.Status != model.PostStatusPublished {
+					if _, ok := g.entries[key]; ok {
+						delete(g.entries, key)
+						g.dirty = true
+					}
+					continue
+				}
+				g.put(key, util.SiteURL("/posts/"+post.ID.String()), time.Time(post.UpdatedAt))
+			}
+			return nil
+		}).Error
+	if err != nil {
+		return err
+	}
+
+	g.lastRefresh = time.Now()
+	return nil
+}
+
+// Total 当前 URL 总数
+func (g *Generator) Total() int {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	return len(g.entries)
+}
+
+// Sitemap 返回第 n 个分片（从 1 开始），不存在时返回 false
+func (g *Generator) Sitemap(n int, compressed bool) ([]byte, bool) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	g.render()
+	if n < 1 || n > len(g.chunks) {
+		return nil, false
+	}
+	if !compressed {
+		return g.chunks[n-1], true
+	}
+
+	if _, ok := g.gzipped[n]; !ok {
+		g.gzipped[n] = compress(g.chunks[n-1])
+	}
+	return g.gzipped[n], true
+}
+
+// Index 生成站点地图索引，分片地址形如 /sitemaps/sitemap-1.xml[.gz]
+func (g *Generator) Index(compressed bool) []byte {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	g.render()
+	suffix := ".xml"
+	if Gzip() {
+		suffix = ".xml.gz"
+	}
+
+	index := sitemapIndex{Xmlns: xmlns}
+	for i := range g.chunks {
+		index.Sitemaps = append(index.Sitemaps, sitemapEntry{
+			Loc:     util.SiteURL(fmt.Sprintf("/sitemaps/sitemap-%d%s", i+1, suffix)),
+			LastMod: formatLastMod(g.chunkMods[i]),
+		})
+	}
+	if compressed {
+		return compress(marshal(index))
+	}
+	return marshal(index)
+}
+
+func (g *Generator) put(key string, loc string, lastMod time.Time) {
+	if old, ok := g.entries[key]; !ok || !old.lastMod.Equal(lastMod) {
+		g.entries[key] = entry{loc: loc, lastMod: lastMod}
+		g.dirty = true
+	}
+	if lastMod.After(g.watermark) {
+		g.watermark = lastMod
+	}
+}
+
+// render 数据有变化时按固定顺序重新切分并渲染全部分片
+func (g *Generator) render() {
+	if !g.dirty {
+		return
+	}
+
+	keys := make([]string, 0, len(g.entries))
+	for key := range g.entries {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	g.chunks = g.chunks[:0]
+	g.chunkMods = g.chunkMods[:0]
+	g.gzipped = make(map[int][]byte)
+	// 没有任何 URL 时也输出一个空的 urlset
+	count := (len(keys) + MaxURLs - 1) / MaxURLs
+	if count == 0 {
+		count = 1
+	}
+	for i := 0; i < count; i++ {
+		start, end := i*MaxURLs, (i+1)*MaxURLs
+		if end > len(keys) {
+			end = len(keys)
+		}
+
+		set := urlSet{Xmlns: xmlns, URLs: make([]urlEntry, 0, end-start)}
+		var chunkMod time.Time
+		for _, key := range keys[start:end] {
+			e := g.entries[key]
+			set.URLs = append(set.URLs, urlEntry{Loc: e.loc, LastMod: formatLastMod(e.lastMod)})
+			if e.lastMod.After(chunkMod) {
+				chunkMod = e.lastMod
+			}
+		}
+		g.chunks = append(g.chunks, marshal(set))
+		g.chunkMods = append(g.chunkMods, chunkMod)
+	}
+	g.dirty = false
+}
+
+func marshal(v interface{}) []byte {
+	b, _ := xml.Marshal(v)
+	return append([]byte(xml.Header), b...)
+}
+
+func compress(b []byte) []byte {
+	var buf bytes.Buffer
+	w := gzip.NewWriter(&buf)
+	w.Write(b)
+	w.Close()
+	return buf.Bytes()
+}
+
+func formatLastMod(t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+	return t.Format(lastModFmt)
+}
+
+func postKey(id string) string {
+	return "post:" + id
+}
+
+func categoryKey(id uint) string {
+	return fmt.Sprintf("category:%010d", id)
+}
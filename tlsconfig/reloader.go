@@ -0,0 +1,110 @@
+package tlsconfig
+
+import (
+	"crypto/tls"
+	"gin-swagger/logger"
+	"os"
+	"sync"
+	"time"
+)
+
+// CertReloader 持有当前证书，定时检查证书和私钥文件的修改时间，变化后重新加载；
+// 新文件加载失败时继续使用旧证书
+type CertReloader struct {
+	certFile string
+	keyFile  string
+	interval time.Duration
+
+	mu        sync.RWMutex
+	cert      *tls.Certificate
+	certModAt time.Time
+	keyModAt  time.Time
+
+	stop chan struct{}
+	done chan struct{}
+}
+
+func NewCertReloader(certFile, keyFile string, interval time.Duration) (*CertReloader, error) {
+	r := &CertReloader{certFile: certFile, keyFile: keyFile, interval: interval}
+	if _, err := r.Reload(); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+// GetCertificate 供 tls.Config.GetCertificate 使用，每次握手取当前证书
+func (r *CertReloader) GetCertificate(*tls.ClientHelloInfo) (*tls.Certificate, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.cert, nil
+}
+
+// Reload 证书或私钥文件修改时间变化时重新加载，返回是否加载了新证书
+func (r *CertReloader) Reload() (bool, error) {
+	certInfo, err := os.Stat(r.certFile)
+	if err != nil {
+		return false, err
+	}
+	keyInfo, err := os.Stat(r.keyFile)
+	if err != nil {
+		return false, err
+	}
+
+	r.mu.RLock()
+	unchanged := r.cert != nil && certInfo.ModTime().Equal(r.certModAt) && keyInfo.ModTime().Equal(r.keyModAt)
+	r.mu.RUnlock()
+	if unchanged {
+		return false, nil
+	}
+
+	// 证书和私钥可能不是同时写入，不匹配时下次检查再试
+	cert, err := tls.LoadX509KeyPair(r.certFile, r.keyFile)
+	if err != nil {
+		return false, err
+	}
+
+	r.mu.Lock()
+	r.cert = &cert
+	r.certModAt = certInfo.ModTime()
+	r.keyModAt = keyInfo.ModTime()
+	r.mu.Unlock()
+	return true, nil
+}
+
+// Start 启动后台定时检查
+func (r *CertReloader) Start() {
+	if r.interval <= 0 {
+		return
+	}
+	r.stop = make(chan struct{})
+	r.done = make(chan struct{})
+
+	go func() {
+		defer close(r.done)
+		ticker := time.NewTicker(r.interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ticker.C:
+				if reloaded, err := r.Reload(); err != nil {
+					logger.GetLogger().Error("reload tls certificate failed", "cert_file", r.certFile, "error", err)
+				} else if reloaded {
+					logger.GetLogger().Info("tls certificate reloaded", "cert_file", r.certFile)
+				}
+			case <-r.stop:
+				return
+			}
+		}
+	}()
+}
+
+// Stop 停止后台检查
+func (r *CertReloader) Stop() {
+	if r.stop == nil {
+		return
+	}
+	close(r.stop)
+	<-r.done
+	r.stop = nil
+}
@@ -0,0 +1,112 @@
+package tlsconfig
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"github.com/spf13/viper"
+	"io/ioutil"
+	"time"
+)
+
+// 客户端证书校验方式
+const (
+	ClientAuthNone    = "none"
+	ClientAuthRequest = "request"
+	ClientAuthRequire = "require"
+)
+
+const defaultReloadInterval = 30 * time.Second
+
+// IdentityMapping 把客户端证书主题映射为服务身份，Subject 可以是 CN 或完整的 DN（如 CN=billing,O=example）
+type IdentityMapping struct {
+	Subject  string `mapstructure:"subject"`
+	Identity string `mapstructure:"identity"`
+}
+
+var (
+	reloader   *CertReloader
+	identities map[string]string
+)
+
+// InitTLS 按配置 server.tls 创建 TLS 配置，未启用时返回 nil；
+// client_auth 为 request 时校验客户端提供的证书，为 require 时必须提供证书
+func InitTLS() (*tls.Config, error) {
+	if !viper.GetBool("server.tls.enabled") {
+		return nil, nil
+	}
+
+	interval := viper.GetDuration("server.tls.reload_interval")
+	if interval == 0 {
+		interval = defaultReloadInterval
+	}
+	r, err := NewCertReloader(viper.GetString("server.tls.cert_file"), viper.GetString("server.tls.key_file"), interval)
+	if err != nil {
+		return nil, fmt.Errorf("load tls certificate: %w", err)
+	}
+
+	config := &tls.Config{
+		MinVersion:     tls.VersionTLS12,
+		GetCertificate: r.GetCertificate,
+	}
+
+	switch mode := viper.GetString("server.tls.client_auth"); mode {
+	case "", ClientAuthNone:
+	case ClientAuthRequest, ClientAuthRequire:
+		pool, err := loadCertPool(viper.GetString("server.tls.client_ca_file"))
+		if err != nil {
+			return nil, err
+		}
+		config.ClientCAs = pool
+		config.ClientAuth = tls.VerifyClientCertIfGiven
+		if mode == ClientAuthRequire {
+			config.ClientAuth = tls.RequireAndVerifyClientCert
+		}
+	default:
+		return nil, fmt.Errorf("unsupported tls client_auth: %s", mode)
+	}
+
+	var mappings []IdentityMapping
+	if err := viper.UnmarshalKey("server.tls.client_identities", &mappings); err != nil {
+		return nil, fmt.Errorf("load tls client_identities: %w", err)
+	}
+	identities = make(map[string]string, len(mappings))
+	for _, mapping := range mappings {
+		identities[mapping.Subject] = mapping.Identity
+	}
+
+	reloader = r
+	return config, nil
+}
+
+// GetReloader 未启用 TLS 时为 nil
+func GetReloader() *CertReloader {
+	return reloader
+}
+
+// Identity 按 server.tls.client_identities 把已校验的客户端证书映射为服务身份，
+// 先匹配完整 DN 再匹配 CN，都未配置时使用 CN
+func Identity(cert *x509.Certificate) string {
+	if identity, ok := identities[cert.Subject.String()]; ok {
+		return identity
+	}
+	if identity, ok := identities[cert.Subject.CommonName]; ok {
+		return identity
+	}
+	return cert.Subject.CommonName
+}
+
+func loadCertPool(file string) (*x509.CertPool, error) {
+	if file == "" {
+		return nil, fmt.Errorf("tls client_ca_file is required when client_auth is enabled")
+	}
+	pem, err := ioutil.ReadFile(file)
+	if err != nil {
+		return nil, fmt.Errorf("load tls client_ca_file: %w", err)
+	}
+	pool := x509.NewCertPool()
+	if !pool.AppendCertsFromPEM(pem) {
+		return nil, fmt.Errorf("no certificates found in %s", file)
+	}
+	return pool, nil
+}
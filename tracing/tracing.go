@@ -0,0 +1,102 @@
+package tracing
+
+import (
+	"context"
+	"fmt"
+	"github.com/spf13/viper"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/exporters/stdout/stdouttrace"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.7.0"
+	"go.opentelemetry.io/otel/trace"
+	"os"
+)
+
+// TracerName 服务内手动创建 span 使用的 tracer 名称
+const TracerName = "gin-swagger"
+
+var provider *sdktrace.TracerProvider
+
+// InitTracer 按配置 tracing 初始化链路追踪，tracing.exporter 为 stdout、file 或 otlp；
+// 未启用时不导出 span，但仍按 W3C Trace Context 传播上下文
+func InitTracer() *sdktrace.TracerProvider {
+	otel.SetTextMapPropagator(Propagator())
+	if !viper.GetBool("tracing.enabled") {
+		return nil
+	}
+
+	exporter, err := newExporter(viper.GetString("tracing.exporter"))
+	if err != nil {
+		panic("failed to create trace exporter, err: " + err.Error())
+	}
+	ratio := 1.0
+	if viper.IsSet("tracing.sample_ratio") {
+		ratio = viper.GetFloat64("tracing.sample_ratio")
+	}
+
+	provider = sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(exporter),
+		sdktrace.WithResource(resource.NewWithAttributes(semconv.SchemaURL, semconv.ServiceNameKey.String(ServiceName()))),
+		// 上游已决定采样时沿用上游的决定
+		sdktrace.WithSampler(sdktrace.ParentBased(sdktrace.TraceIDRatioBased(ratio))),
+	)
+	otel.SetTracerProvider(provider)
+	return provider
+}
+
+func GetProvider() *sdktrace.TracerProvider {
+	return provider
+}
+
+// Shutdown 导出尚未发送的 span 并关闭导出器，未启用时直接返回
+func Shutdown(ctx context.Context) error {
+	if provider == nil {
+		return nil
+	}
+	return provider.Shutdown(ctx)
+}
+
+// ServiceName 上报的服务名，默认 gin-swagger
+func ServiceName() string {
+	if name := viper.GetString("tracing.service_name"); name != "" {
+		return name
+	}
+	return "gin-swagger"
+}
+
+// Propagator W3C Trace Context 和 Baggage 传播器
+func Propagator() propagation.TextMapPropagator {
+	return propagation.NewCompositeTextMapPropagator(propagation.TraceContext{}, propagation.Baggage{})
+}
+
+// Tracer 获取服务的 tracer
+func Tracer() trace.Tracer {
+	return otel.Tracer(TracerName)
+}
+
+func newExporter(name string) (sdktrace.SpanExporter, error) {
+	switch name {
+	case "", "stdout":
+		return stdouttrace.New(stdouttrace.WithWriter(os.Stdout))
+	case "file":
+		// 离线开发时写入文件，每行一个 span
+		file, err := os.OpenFile(viper.GetString("tracing.file"), os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+		if err != nil {
+			return nil, err
+		}
+		return stdouttrace.New(stdouttrace.WithWriter(file))
+	case "otlp":
+		options := []otlptracehttp.Option{otlptracehttp.WithEndpoint(viper.GetString("tracing.otlp.endpoint"))}
+		if viper.GetBool("tracing.otlp.insecure") {
+			options = append(options, otlptracehttp.WithInsecure())
+		}
+		if headers := viper.GetStringMapString("tracing.otlp.headers"); len(headers) > 0 {
+			options = append(options, otlptracehttp.WithHeaders(headers))
+		}
+		return otlptracehttp.New(context.Background(), options...)
+	}
+	return nil, fmt.Errorf("unsupported trace exporter: %s", name)
+}
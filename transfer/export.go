@@ -0,0 +1,117 @@
+package transfer
+
+import (
+	"archive/zip"
+	"encoding/csv"
+	"encoding/json"
+	"gopkg.in/yaml.v2"
+	"io"
+	"strconv"
+	"strings"
+)
+
+var csvHeader = []string{"id", "title", "category", "tags", "status", "head_img", "comments_disabled", "created_at", "content"}
+
+// csvTagSeparator CSV 中多个标签的分隔符
+const csvTagSeparator = "|"
+
+// Writer 逐条写出导出记录
+type Writer interface {
+	Write(record Record) error
+	// Close 写出剩余内容，不关闭底层 io.Writer
+	Close() error
+}
+
+// ContentType 导出格式对应的响应类型和文件名
+func ContentType(format string) (contentType string, filename string) {
+	switch format {
+	case FormatCSV:
+		return "text/csv; charset=utf-8", "posts.csv"
+	case FormatMarkdown:
+		return "application/zip", "posts.zip"
+	}
+	return "application/x-ndjson", "posts.ndjson"
+}
+
+// NewWriter 按格式创建导出写入器
+func NewWriter(format string, w io.Writer) (Writer, error) {
+	if err := ValidFormat(format); err != nil {
+		return nil, err
+	}
+
+	switch format {
+	case FormatCSV:
+		cw := csv.NewWriter(w)
+		if err := cw.Write(csvHeader); err != nil {
+			return nil, err
+		}
+		return &csvWriter{w: cw}, nil
+	case FormatMarkdown:
+		return &markdownWriter{w: zip.NewWriter(w)}, nil
+	}
+	return &ndjsonWriter{enc: json.NewEncoder(w)}, nil
+}
+
+type ndjsonWriter struct {
+	enc *json.Encoder
+}
+
+func (n *ndjsonWriter) Write(record Record) error {
+	return n.enc.Encode(record)
+}
+
+func (n *ndjsonWriter) Close() error {
+	return nil
+}
+
+type csvWriter struct {
+	w *csv.Writer
+}
+
+func (c *csvWriter) Write(record Record) error {
+	err := c.w.Write([]string{
+		record.ID,
+		record.Title,
+		record.Category,
+		strings.Join(record.Tags, csvTagSeparator),
+		record.Status,
+		record.HeadImg,
+		strconv.FormatBool(record.CommentsDisabled),
+		record.CreatedAt,
+		record.Content,
+	})
+	if err != nil {
+		return err
+	}
+	// 每条记录立即写出，保证流式下载
+	c.w.Flush()
+	return c.w.Error()
+}
+
+func (c *csvWriter) Close() error {
+	c.w.Flush()
+	return c.w.Error()
+}
+
+// markdownWriter 每篇文章一个 Markdown 文件，元数据写在 YAML front matter 中
+type markdownWriter struct {
+	w *zip.Writer
+}
+
+func (m *markdownWriter) Write(record Record) error {
+	frontMatter, err := yaml.Marshal(record)
+	if err != nil {
+		return err
+	}
+
+	f, err := m.w.Create(record.ID + ".md")
+	if err != nil {
+		return err
+	}
+	_, err = io.WriteString(f, "---\n"+string(frontMatter)+"---\n\n"+record.Content)
+	return err
+}
+
+func (m *markdownWriter) Close() error {
+	return m.w.Close()
+}
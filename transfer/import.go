@@ -0,0 +1,368 @@
+package transfer
+
+import (
+	"archive/zip"
+	"bufio"
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"gin-swagger/dto"
+	"gin-swagger/errcode"
+	"gin-swagger/i18n"
+	"gin-swagger/logger"
+	"gin-swagger/model"
+	"github.com/gin-gonic/gin/binding"
+	"gopkg.in/yaml.v2"
+	"gorm.io/gorm"
+	"io"
+	"io/ioutil"
+	"path"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// maxLineSize NDJSON 单行最大长度
+const maxLineSize = 16 << 20
+
+// Markdown zip 包解压后单个文件和全部文件的大小上限；上传大小只限制压缩后的数据，需防止高压缩比的文件耗尽内存
+const (
+	MaxEntrySize   = 4 << 20
+	MaxArchiveSize = 64 << 20
+)
+
+// ErrArchiveTooLarge zip 包中单个文件或全部文件解压后超过大小上限
+var ErrArchiveTooLarge = fmt.Errorf("archive exceeds %dMB per file or %dMB in total when uncompressed", MaxEntrySize>>20, MaxArchiveSize>>20)
+
+// Item 解析出的单条导入记录，Source 为行号或文件名，解析失败时 Err 非空
+type Item struct {
+	Source string
+	Record Record
+	Err    error
+}
+
+// Result 单条导入结果
+type Result struct {
+	Source  string               `json:"source"`
+	Title   string               `json:"title"`
+	ID      string               `json:"id,omitempty"`
+	Success bool                 `json:"success"`
+	Error   errcode.Code         `json:"error,omitempty"`
+	Msg     string               `json:"msg"`
+	Errors  []errcode.FieldError `json:"errors,omitempty"`
+}
+
+// Parse 按格式解析导入文件，整个文件无法解析时返回错误
+func Parse(format string, data []byte) ([]Item, error) {
+	if err := ValidFormat(format); err != nil {
+		return nil, err
+	}
+
+	switch format {
+	case FormatCSV:
+		return parseCSV(data)
+	case FormatMarkdown:
+		return parseMarkdownZip(data)
+	}
+	return parseNDJSON(data)
+}
+
+func parseNDJSON(data []byte) ([]Item, error) {
+	var items []Item
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	scanner.Buffer(make([]byte, 64*1024), maxLineSize)
+	for line := 1; scanner.Scan(); line++ {
+		text := bytes.TrimSpace(scanner.Bytes())
+		if len(text) == 0 {
+			continue
+		}
+		item := Item{Source: "line " + strconv.Itoa(line)}
+		item.Err = json.Unmarshal(text, &item.Record)
+		items = append(items, item)
+	}
+	return items, scanner.Err()
+}
+
+func parseCSV(data []byte) ([]Item, error) {
+	reader := csv.NewReader(bytes.NewReader(data))
+	reader.FieldsPerRecord = -1
+	header, err := reader.Read()
+	if err != nil {
+		return nil, err
+	}
+	columns := make(map[string]int, len(header))
+	for i, name := range header {
+		columns[strings.TrimSpace(name)] = i
+	}
+	for _, name := range []string{"title", "content"} {
+		if _, ok := columns[name]; !ok {
+			return nil, fmt.Errorf("missing csv column %q", name)
+		}
+	}
+
+	var items []Item
+	for line := 2; ; line++ {
+		row, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		item := Item{Source: "line " + strconv.Itoa(line)}
+		if err != nil {
+			item.Err = err
+			items = append(items, item)
+			continue
+		}
+
+		get := func(name string) string {
+			if i, ok := columns[name]; ok && i < len(row) {
+				return row[i]
+			}
+			return ""
+		}
+		item.Record = Record{
+			ID:        get("id"),
+			Title:     get("title"),
+			Category:  get("category"),
+			Status:    get("status"),
+			HeadImg:   get("head_img"),
+			CreatedAt: get("created_at"),
+			Content:   get("content"),
+		}
+		if tags := get("tags"); tags != "" {
+			item.Record.Tags = strings.Split(tags, csvTagSeparator)
+		}
+		if disabled := get("comments_disabled"); disabled != "" {
+			item.Record.CommentsDisabled, item.Err = strconv.ParseBool(disabled)
+		}
+		items = append(items, item)
+	}
+	return items, nil
+}
+
+func parseMarkdownZip(data []byte) ([]Item, error) {
+	reader, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		return nil, err
+	}
+
+	var items []Item
+	var total int64
+	for _, file := range reader.File {
+		if file.FileInfo().IsDir() || path.Ext(file.Name) != ".md" {
+			continue
+		}
+		// 先按目录中记录的大小拒绝，读取时再按实际解压的字节数限制，记录的大小可能不实
+		limit := MaxArchiveSize - total
+		if limit > MaxEntrySize {
+			limit = MaxEntrySize
+		}
+		if file.UncompressedSize64 > uint64(limit) {
+			return nil, ErrArchiveTooLarge
+		}
+
+		item := Item{Source: file.Name}
+		content, err := readZipFile(file, limit)
+		if errors.Is(err, ErrArchiveTooLarge) {
+			return nil, err
+		}
+		total += int64(len(content))
+		if err != nil {
+			item.Err = err
+		} else {
+			item.Record, item.Err = parseMarkdownFile(content)
+		}
+		items = append(items, item)
+	}
+	return items, nil
+}
+
+// readZipFile 读取 zip 包中的文件，解压后超过 limit 字节时返回 ErrArchiveTooLarge
+func readZipFile(file *zip.File, limit int64) ([]byte, error) {
+	f, err := file.Open()
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	content, err := ioutil.ReadAll(io.LimitReader(f, limit+1))
+	if err != nil {
+		return content, err
+	}
+	if int64(len(content)) > limit {
+		return nil, ErrArchiveTooLarge
+	}
+	return content, nil
+}
+
+// parseMarkdownFile 解析带 YAML front matter 的 Markdown 文件
+func parseMarkdownFile(content []byte) (Record, error) {
+	var record Record
+	text := strings.ReplaceAll(string(content), "\r\n", "\n")
+	if !strings.HasPrefix(text, "---\n") {
+		return record, errors.New("missing front matter")
+	}
+	end := strings.Index(text[4:], "\n---\n")
+	if end < 0 {
+		return record, errors.New("unterminated front matter")
+	}
+	if err := yaml.Unmarshal([]byte(text[4:4+end]), &record); err != nil {
+		return record, err
+	}
+	record.Content = strings.TrimLeft(text[4+end+5:], "\n")
+	return record, nil
+}
+
+// Importer 将解析出的记录导入为指定用户的文章
+type Importer struct {
+	DB     *gorm.DB
+	UserID uint
+	// DryRun 为 true 时只做校验，不写入数据库
+	DryRun bool
+	// Lang 结果提示信息的语言
+	Lang string
+	// Logger 记录非业务错误，为空时使用全局日志
+	Logger *logger.Logger
+
+	categories map[string]uint
+}
+
+// Import 逐条校验并导入，返回每条结果和成功创建的文章
+func (im *Importer) Import(items []Item) ([]Result, []model.Post) {
+	im.categories = make(map[string]uint)
+	results := make([]Result, 0, len(items))
+	var created []model.Post
+
+	for _, item := range items {
+		result := Result{Source: item.Source, Title: item.Record.Title}
+		if item.Err != nil {
+			im.fail(&result, errcode.New(errcode.ImportRecordParseFailed, item.Err.Error()))
+			results = append(results, result)
+			continue
+		}
+
+		post, err := im.build(item.Record)
+		if err == nil && !im.DryRun {
+			err = im.DB.Transaction(func(tx *gorm.DB) error {
+				return im.create(tx, &post, item.Record.Tags)
+			})
+		}
+		if err != nil {
+			im.fail(&result, err)
+			results = append(results, result)
+			continue
+		}
+
+		result.Success = true
+		result.Msg = i18n.T(im.Lang, "import_record_valid")
+		if !im.DryRun {
+			result.ID = post.ID.String()
+			result.Msg = i18n.T(im.Lang, "import_record_imported")
+			created = append(created, post)
+		}
+		results = append(results, result)
+	}
+	return results, created
+}
+
+func (im *Importer) log() *logger.Logger {
+	if im.Logger != nil {
+		return im.Logger
+	}
+	return logger.GetLogger()
+}
+
+// fail 记录单条失败原因，非业务错误只记日志不返回细节
+func (im *Importer) fail(result *Result, err error) {
+	e := errcode.From(err)
+	if e.Code == errcode.Internal {
+		im.log().Error("import record failed", "source", result.Source, "error", err)
+	}
+	result.Error = e.Code
+	result.Msg = e.Message(im.Lang)
+	if fields := e.FieldErrors(im.Lang); len(fields) > 0 {
+		result.Errors = fields
+	}
+}
+
+// build 校验记录并转换为文章，校验规则与创建文章接口一致
+func (im *Importer) build(record Record) (model.Post, error) {
+	var post model.Post
+	categoryID, err := im.categoryID(record.Category)
+	if err != nil {
+		return post, err
+	}
+
+	request := dto.CreatePostRequest{
+		CategoryID:       categoryID,
+		Title:            record.Title,
+		HeadImg:          record.HeadImg,
+		Content:          record.Content,
+		Status:           record.Status,
+		CommentsDisabled: record.CommentsDisabled,
+	}
+	if err := binding.Validator.ValidateStruct(request); err != nil {
+		return post, errcode.Invalid(err)
+	}
+
+	post = model.Post{
+		UserID:           im.UserID,
+		CategoryID:       request.CategoryID,
+		Title:            request.Title,
+		HeadImg:          request.HeadImg,
+		Content:          request.Content,
+		Status:           request.Status,
+		CommentsDisabled: request.CommentsDisabled,
+	}
+	if post.Status == "" {
+		post.Status = model.PostStatusPublished
+	}
+	if record.CreatedAt != "" {
+		createdAt, err := time.Parse(timeLayout, record.CreatedAt)
+		if err != nil {
+			return post, errcode.New(errcode.ImportCreatedAtInvalid, record.CreatedAt)
+		}
+		post.CreatedAt = model.Time(createdAt)
+	}
+	if err := post.RenderContent(); err != nil {
+		return post, err
+	}
+	return post, nil
+}
+
+// categoryID 按名称查找分类
+func (im *Importer) categoryID(name string) (uint, error) {
+	if name == "" {
+		return 0, errcode.New(errcode.ImportCategoryRequired)
+	}
+	if id, ok := im.categories[name]; ok {
+		return id, nil
+	}
+
+	var category model.Category
+	if err := im.DB.Where("name = ?", name).First(&category).Error; err != nil {
+		return 0, errcode.New(errcode.ImportCategoryNotFound, name)
+	}
+	im.categories[name] = category.ID
+	return category.ID, nil
+}
+
+func (im *Importer) create(tx *gorm.DB, post *model.Post, tagNames []string) error {
+	if err := tx.Create(post).Error; err != nil {
+		return err
+	}
+	if len(tagNames) == 0 {
+		return nil
+	}
+
+	tags := make([]model.Tag, 0, len(tagNames))
+	for _, name := range tagNames {
+		tag := model.Tag{Name: name}
+		if err := tx.Where(model.Tag{Name: name}).FirstOrCreate(&tag).Error; err != nil {
+			return err
+		}
+		tags = append(tags, tag)
+	}
+	post.Tags = tags
+	return tx.Model(post).Association("Tags").Replace(tags)
+}
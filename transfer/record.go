@@ -0,0 +1,73 @@
+package transfer
+
+import (
+	"fmt"
+	"gin-swagger/model"
+	"strings"
+	"time"
+)
+
+// 支持的导入导出格式
+const (
+	FormatNDJSON   = "ndjson"
+	FormatCSV      = "csv"
+	FormatMarkdown = "markdown"
+)
+
+const timeLayout = time.RFC3339
+
+// Record 导入导出时文章的通用表示，分类和标签按名称关联
+type Record struct {
+	ID               string   `json:"id" yaml:"id"`
+	Title            string   `json:"title" yaml:"title"`
+	Category         string   `json:"category" yaml:"category"`
+	Tags             []string `json:"tags" yaml:"tags"`
+	Status           string   `json:"status" yaml:"status"`
+	HeadImg          string   `json:"head_img" yaml:"head_img"`
+	CommentsDisabled bool     `json:"comments_disabled" yaml:"comments_disabled"`
+	CreatedAt        string   `json:"created_at" yaml:"created_at"`
+	Content          string   `json:"content" yaml:"-"`
+}
+
+// FromPost 将文章转换为导出记录，需预加载 Category 和 Tags
+func FromPost(post model.Post) Record {
+	record := Record{
+		ID:               post.ID.String(),
+		Title:            post.Title,
+		Tags:             make([]string, 0, len(post.Tags)),
+		Status:           post.Status,
+		HeadImg:          post.HeadImg,
+		CommentsDisabled: post.CommentsDisabled,
+		CreatedAt:        time.Time(post.CreatedAt).Format(timeLayout),
+		Content:          post.Content,
+	}
+	if post.Category != nil {
+		record.Category = post.Category.Name
+	}
+	for _, tag := range post.Tags {
+		record.Tags = append(record.Tags, tag.Name)
+	}
+	return record
+}
+
+// ValidFormat 校验格式名称
+func ValidFormat(format string) error {
+	switch format {
+	case FormatNDJSON, FormatCSV, FormatMarkdown:
+		return nil
+	}
+	return fmt.Errorf("unsupported format %q", format)
+}
+
+// FormatOf 根据文件扩展名推断格式
+func FormatOf(filename string) string {
+	switch {
+	case strings.HasSuffix(filename, ".ndjson"), strings.HasSuffix(filename, ".jsonl"):
+		return FormatNDJSON
+	case strings.HasSuffix(filename, ".csv"):
+		return FormatCSV
+	case strings.HasSuffix(filename, ".zip"):
+		return FormatMarkdown
+	}
+	return ""
+}
@@ -0,0 +1,122 @@
+package trash
+
+import (
+	"gin-swagger/logger"
+	"gin-swagger/model"
+	"github.com/spf13/viper"
+	"gorm.io/gorm"
+	"time"
+)
+
+const purgeBatchSize = 500
+
+// Purge 彻底删除文章及其评论、点赞、收藏和标签关联
+func Purge(db *gorm.DB, postIDs ...string) error {
+	if len(postIDs) == 0 {
+		return nil
+	}
+
+	return db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Unscoped().Where("post_id IN ?", postIDs).Delete(&model.Comment{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("post_id IN ?", postIDs).Delete(&model.PostLike{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("post_id IN ?", postIDs).Delete(&model.PostBookmark{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Exec("DELETE FROM post_tags WHERE post_id IN ?", postIDs).Error; err != nil {
+			return err
+		}
+		return tx.Unscoped().Where("id IN ?", postIDs).Delete(&model.Post{}).Error
+	})
+}
+
+// Purger 回收站清理任务，定期彻底删除超过保留期的文章
+type Purger struct {
+	db        *gorm.DB
+	retention time.Duration
+	interval  time.Duration
+
+	stop chan struct{}
+	done chan struct{}
+}
+
+var purger *Purger
+
+func NewPurger(db *gorm.DB, retention time.Duration, interval time.Duration) *Purger {
+	return &Purger{db: db, retention: retention, interval: interval}
+}
+
+func InitPurger(db *gorm.DB) *Purger {
+	retention := viper.GetDuration("post.trash_retention")
+	if retention <= 0 {
+		retention = 30 * 24 * time.Hour
+	}
+	interval := viper.GetDuration("post.purge_interval")
+	if interval <= 0 {
+		interval = time.Hour
+	}
+
+	purger = NewPurger(db, retention, interval)
+	return purger
+}
+
+func GetPurger() *Purger {
+	return purger
+}
+
+// Start 启动后台定时清理
+func (p *Purger) Start() {
+	p.stop = make(chan struct{})
+	p.done = make(chan struct{})
+
+	go func() {
+		defer close(p.done)
+		ticker := time.NewTicker(p.interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ticker.C:
+				if purged, err := p.PurgeExpired(); err != nil {
+					logger.GetLogger().Error("purge trashed posts failed", "error", err)
+				} else if purged > 0 {
+					logger.GetLogger().Info("purged trashed posts", "count", purged)
+				}
+			case <-p.stop:
+				return
+			}
+		}
+	}()
+}
+
+// Stop 停止后台清理
+func (p *Purger) Stop() {
+	if p.stop == nil {
+		return
+	}
+	close(p.stop)
+	<-p.done
+	p.stop = nil
+}
+
+// PurgeExpired 分批彻底删除超过保留期的文章，返回删除条数
+func (p *Purger) PurgeExpired() (int, error) {
+	deadline := time.Now().Add(-p.retention)
+	total := 0
+	for {
+		var ids []string
+		err := p.db.Unscoped().Model(&model.Post{}).
+			Where("deleted_at IS NOT NULL AND deleted_at < ?", deadline).
+			Limit(purgeBatchSize).Pluck("id", &ids).Error
+		if err != nil || len(ids) == 0 {
+			return total, err
+		}
+		if err := Purge(p.db, ids...); err != nil {
+			return total, err
+		}
+		total += len(ids)
+	}
+}
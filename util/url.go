@@ -0,0 +1,12 @@
+package util
+
+import (
+	"github.com/spf13/viper"
+	"strings"
+)
+
+// SiteURL 拼接站点对外访问的绝对地址
+func SiteURL(path string) string {
+	base := strings.TrimRight(viper.GetString("site.url"), "/")
+	return base + "/" + strings.TrimLeft(path, "/")
+}
@@ -0,0 +1,26 @@
+package validation
+
+import (
+	"github.com/go-playground/validator/v10"
+	"regexp"
+)
+
+var (
+	telephonePattern = regexp.MustCompile(`^1[3-9]\d{9}$`)
+	slugPattern      = regexp.MustCompile(`^[a-z0-9]+(?:-[a-z0-9]+)*$`)
+	hexColorPattern  = regexp.MustCompile(`^#(?:[0-9a-fA-F]{3}|[0-9a-fA-F]{6})$`)
+)
+
+// rules 自定义校验规则，键为 binding 标签中使用的规则名
+var rules = map[string]validator.Func{
+	"telephone": matches(telephonePattern),
+	"slug":      matches(slugPattern),
+	// 与内置 hexcolor 不同，不接受带透明度的写法
+	"hex_color": matches(hexColorPattern),
+}
+
+func matches(pattern *regexp.Regexp) validator.Func {
+	return func(fl validator.FieldLevel) bool {
+		return pattern.MatchString(fl.Field().String())
+	}
+}
@@ -0,0 +1,86 @@
+package validation
+
+import (
+	"errors"
+	"gin-swagger/i18n"
+	"github.com/gin-gonic/gin/binding"
+	"github.com/go-playground/locales/en"
+	"github.com/go-playground/locales/zh"
+	ut "github.com/go-playground/universal-translator"
+	"github.com/go-playground/validator/v10"
+	entranslations "github.com/go-playground/validator/v10/translations/en"
+	zhtranslations "github.com/go-playground/validator/v10/translations/zh"
+	"reflect"
+	"strings"
+)
+
+var universal = ut.New(zh.New(), zh.New(), en.New())
+
+// defaultTranslations 各语言内置校验规则的翻译
+var defaultTranslations = map[string]func(*validator.Validate, ut.Translator) error{
+	"zh": zhtranslations.RegisterDefaultTranslations,
+	"en": entranslations.RegisterDefaultTranslations,
+}
+
+// InitValidator 配置 gin 的参数校验器：字段名取 JSON 名称，注册自定义规则和各语言翻译，需在 i18n.InitI18n 之后调用
+func InitValidator() {
+	validate, ok := binding.Validator.Engine().(*validator.Validate)
+	if !ok {
+		panic(errors.New("unexpected validator engine"))
+	}
+
+	validate.RegisterTagNameFunc(fieldName)
+	for tag, rule := range rules {
+		if err := validate.RegisterValidation(tag, rule); err != nil {
+			panic(err)
+		}
+	}
+
+	for lang, register := range defaultTranslations {
+		trans, _ := universal.GetTranslator(lang)
+		if err := register(validate, trans); err != nil {
+			panic(err)
+		}
+		for tag := range rules {
+			if err := registerTranslation(validate, trans, lang, tag); err != nil {
+				panic(err)
+			}
+		}
+	}
+}
+
+// fieldName 校验错误中使用的字段名，依次取 json、form 标签，都没有时使用结构体字段名
+func fieldName(field reflect.StructField) string {
+	for _, key := range []string{"json", "form"} {
+		name := strings.SplitN(field.Tag.Get(key), ",", 2)[0]
+		if name == "-" {
+			return ""
+		}
+		if name != "" {
+			return name
+		}
+	}
+	return field.Name
+}
+
+// registerTranslation 自定义规则的提示文案取自 i18n 消息目录中的 validation_<规则名>
+func registerTranslation(validate *validator.Validate, trans ut.Translator, lang string, tag string) error {
+	return validate.RegisterTranslation(tag, trans, func(t ut.Translator) error {
+		return t.Add(tag, i18n.T(lang, "validation_"+tag), true)
+	}, func(t ut.Translator, fe validator.FieldError) string {
+		message, err := t.T(tag, fe.Field())
+		if err != nil {
+			return fe.Error()
+		}
+		return message
+	})
+}
+
+// Translate 将单个字段的校验错误翻译为指定语言，不支持的语言使用默认语言
+func Translate(lang string, fe validator.FieldError) string {
+	trans, found := universal.GetTranslator(lang)
+	if !found {
+		trans, _ = universal.GetTranslator(i18n.DefaultLanguage())
+	}
+	return fe.Translate(trans)
+}